@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/hostathome/cli/internal/backup"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/schedule"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const agentUnit = "hostathome-backup"
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run scheduled backups",
+	Long: `Run scheduled backups for the servers in the current directory.
+
+By default the agent keeps running and checks schedules every minute.
+With --once it runs any due backups and exits, for use from a systemd timer
+(see "hostathome agent install") or cron.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		once, _ := cmd.Flags().GetBool("once")
+
+		if once {
+			return runDueBackups(time.Now())
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ui.Info("Backup agent started (Ctrl+C to stop)")
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			if err := runDueBackups(time.Now()); err != nil {
+				ui.Error("%v", err)
+			}
+			select {
+			case <-ctx.Done():
+				ui.Info("Backup agent stopped")
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+var agentInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a systemd timer that runs scheduled backups",
+	Long:  "Write a systemd user service and timer that run 'hostathome agent --once' every minute in the current directory.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		workDir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+
+		unitDir := filepath.Join(homeDir, ".config", "systemd", "user")
+		if err := os.MkdirAll(unitDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", unitDir, err)
+		}
+
+		service := fmt.Sprintf(`[Unit]
+Description=HostAtHome scheduled backups
+
+[Service]
+Type=oneshot
+WorkingDirectory=%s
+ExecStart=%s agent --once
+`, workDir, exe)
+
+		timer := `[Unit]
+Description=Run HostAtHome scheduled backups
+
+[Timer]
+OnCalendar=minutely
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`
+
+		servicePath := filepath.Join(unitDir, agentUnit+".service")
+		timerPath := filepath.Join(unitDir, agentUnit+".timer")
+		if err := os.WriteFile(servicePath, []byte(service), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", servicePath, err)
+		}
+		if err := os.WriteFile(timerPath, []byte(timer), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", timerPath, err)
+		}
+
+		ui.Success("Installed systemd units")
+		ui.Detail("Service", servicePath)
+		ui.Detail("Timer", timerPath)
+		ui.Detail("Directory", workDir)
+		ui.Println()
+		ui.Info("Enable with: systemctl --user daemon-reload && systemctl --user enable --now %s.timer", agentUnit)
+
+		return nil
+	},
+}
+
+// runDueBackups backs up every server in the current directory whose schedule
+// has come due since its latest backup, then prunes old backups
+func runDueBackups(now time.Time) error {
+	instances, err := server.List()
+	if err != nil {
+		return fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	for _, instance := range instances {
+		manifest, err := server.LoadManifest(instance)
+		if err != nil {
+			ui.Error("%s: %v", instance, err)
+			continue
+		}
+		if manifest.Backup == nil || manifest.Backup.Cron == "" {
+			continue
+		}
+
+		sched, err := schedule.Parse(manifest.Backup.Cron)
+		if err != nil {
+			ui.Error("%s: %v", instance, err)
+			continue
+		}
+
+		backups, err := backup.List(instance)
+		if err != nil {
+			ui.Error("%s: %v", instance, err)
+			continue
+		}
+		if len(backups) > 0 {
+			// Backups record UTC, schedules are in local time like cron
+			next := sched.Next(backups[0].Created.Local())
+			if next.IsZero() || next.After(now) {
+				continue
+			}
+		}
+
+		game, err := registry.GetGame(manifest.Game)
+		if err != nil {
+			ui.Error("%s: %v", instance, err)
+			continue
+		}
+
+		ui.Step("Scheduled backup of %s", serverTitle(game, instance))
+		mode := manifest.Backup.Mode
+		info, err := createBackup(game, instance, mode == "pause", mode == "stop")
+		if err != nil {
+			ui.Error("%s: %v", instance, err)
+			continue
+		}
+		ui.Detail("Archive", info.Path())
+
+		pruned, err := backup.Prune(instance, manifest.Backup.Retention, false)
+		if err != nil {
+			ui.Error("%s: %v", instance, err)
+		}
+		for _, b := range pruned {
+			ui.Detail("Pruned", b.Name)
+		}
+		copies, err := backup.PruneSafetyCopies(instance, false)
+		if err != nil {
+			ui.Error("%s: %v", instance, err)
+		}
+		for _, c := range copies {
+			ui.Detail("Pruned", c.Name)
+		}
+	}
+	return nil
+}
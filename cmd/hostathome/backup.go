@@ -0,0 +1,490 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hostathome/cli/internal/backup"
+	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/schedule"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup <game>",
+	Short: "Back up a game server",
+	Long:  "Create a compressed, timestamped archive of the server's data and configs directories.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+		pause, _ := cmd.Flags().GetBool("pause")
+		stop, _ := cmd.Flags().GetBool("stop")
+
+		game, err := registry.GetGame(gameName)
+		if err != nil {
+			reportGameError(gameName, err)
+			return err
+		}
+
+		if _, err := os.Stat(server.Dir(instance)); err != nil {
+			ui.Error("%s is not installed", serverTitle(game, instance))
+			ui.Info("Install with: hostathome install %s", serverRef(gameName, instance))
+			return fmt.Errorf("server directory %s not found", server.Dir(instance))
+		}
+
+		info, err := createBackup(game, instance, pause, stop)
+		if err != nil {
+			return err
+		}
+
+		ui.Println()
+		ui.Success("Backup of %s created.", serverTitle(game, instance))
+		ui.Println()
+		ui.Detail("Archive", info.Path())
+		ui.Detail("Size", ui.FormatBytes(info.Size))
+		ui.Detail("SHA256", info.SHA256)
+
+		return nil
+	},
+}
+
+var backupsCmd = &cobra.Command{
+	Use:   "backups <game>",
+	Short: "List backups of a game server",
+	Long:  "Show the available backup archives for a game server, newest first.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+
+		backups, err := backup.List(instance)
+		if err != nil {
+			return fmt.Errorf("failed to list backups: %w", err)
+		}
+
+		if structured() {
+			if backups == nil {
+				backups = []*backup.Info{}
+			}
+			return printData(backups)
+		}
+
+		copies, err := backup.SafetyCopies(instance)
+		if err != nil {
+			return fmt.Errorf("failed to list safety copies: %w", err)
+		}
+
+		if len(backups) == 0 && len(copies) == 0 {
+			ui.Info("No backups found for %s", instance)
+			ui.Info("Create one: hostathome backup %s", serverRef(gameName, instance))
+			return nil
+		}
+
+		ui.Title("Backups")
+		ui.Println()
+
+		headers := []string{"BACKUP", "CREATED", "SIZE", "SHA256", "IMAGE"}
+		var rows [][]string
+		for _, b := range backups {
+			rows = append(rows, []string{
+				b.Name,
+				b.Created.Local().Format("2006-01-02 15:04:05"),
+				ui.FormatBytes(b.Size),
+				b.SHA256[:min(12, len(b.SHA256))],
+				b.Image,
+			})
+		}
+		if len(rows) > 0 {
+			ui.Table(headers, rows)
+		} else {
+			ui.Println("   none")
+		}
+
+		if len(copies) > 0 {
+			ui.Println()
+			ui.Title("Safety copies")
+			ui.Println()
+			rows = nil
+			for _, c := range copies {
+				rows = append(rows, []string{c.Name, c.Created.Local().Format("2006-01-02 15:04:05"), ui.FormatBytes(c.Size)})
+			}
+			ui.Table([]string{"COPY", "CREATED", "SIZE"}, rows)
+			ui.Println()
+			ui.Info("Data moved aside by restores; 'hostathome prune %s' keeps only the newest", serverRef(gameName, instance))
+		}
+
+		ui.Println()
+		ui.Info("Restore: hostathome restore %s <backup>", serverRef(gameName, instance))
+
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <game> <backup>",
+	Short: "Restore a game server from a backup",
+	Long: `Stop the server, verify the backup checksum, move the current data and configs
+aside as a safety copy, extract the backup and start the server again.
+Use "latest" to restore the newest backup.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		game, err := registry.GetGame(gameName)
+		if err != nil {
+			reportGameError(gameName, err)
+			return err
+		}
+
+		info, err := backup.Find(instance, args[1])
+		if err != nil {
+			ui.Error("%v", err)
+			ui.Info("List backups: hostathome backups %s", serverRef(gameName, instance))
+			return err
+		}
+
+		if info.Game != game.Name {
+			if !force {
+				ui.Error("Backup %s was made for %s, not %s", info.Name, info.Game, game.Name)
+				ui.Detail("Fix", "Use --force to restore it anyway")
+				return fmt.Errorf("backup game mismatch")
+			}
+			ui.Warning("Backup was made for %s, restoring anyway (--force)", info.Game)
+		} else if info.Image != game.Image {
+			ui.Warning("Backup was made with image %s, server now uses %s", info.Image, game.Image)
+		}
+
+		spinner := ui.NewSpinner(fmt.Sprintf("Verifying %s", info.Name))
+		spinner.Start()
+		if err := info.Verify(); err != nil {
+			spinner.Stop(false)
+			ui.Error("Backup is corrupt: %v", err)
+			return err
+		}
+		spinner.Stop(true)
+
+		state, err := docker.ContainerState(instance)
+		if err != nil {
+			ui.Error("Cannot check container state: %v", err)
+			return fmt.Errorf("failed to get container state: %w", err)
+		}
+		wasRunning := state == "running" || state == "paused"
+
+		if wasRunning {
+			spinner = ui.NewSpinner(fmt.Sprintf("Stopping %s", serverTitle(game, instance)))
+			spinner.Start()
+			if state == "paused" {
+				_ = docker.UnpauseContainer(instance)
+			}
+			if _, err := docker.StopContainer(instance, docker.StopOptions{}); err != nil {
+				spinner.Stop(false)
+				return fmt.Errorf("failed to stop container: %w", err)
+			}
+			spinner.Stop(true)
+		}
+
+		spinner = ui.NewSpinner(fmt.Sprintf("Restoring %s", info.Name))
+		spinner.Start()
+		safetyDir, err := backup.Restore(info)
+		if err != nil {
+			spinner.Stop(false)
+			ui.Error("%v", err)
+			ui.Info("Current data was left in place")
+			return err
+		}
+		spinner.Stop(true)
+
+		if wasRunning {
+			spinner = ui.NewSpinner(fmt.Sprintf("Starting %s", serverTitle(game, instance)))
+			spinner.Start()
+			if err := docker.StartContainer(instance); err != nil {
+				spinner.Stop(false)
+				return fmt.Errorf("failed to start container: %w", err)
+			}
+			spinner.Stop(true)
+		}
+
+		ui.Println()
+		ui.Success("%s restored from %s.", serverTitle(game, instance), info.Name)
+		ui.Println()
+		ui.Detail("Safety copy", safetyDir)
+		if !wasRunning {
+			ui.Info("Start with: hostathome run %s", serverRef(gameName, instance))
+		}
+
+		return nil
+	},
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule <game>",
+	Short: "Configure scheduled backups for a game server",
+	Long: `Set the backup schedule and retention policy for a game server. Without
+flags, show the current settings. Scheduled backups are run by "hostathome agent".`,
+	Example: `  hostathome schedule minecraft --cron @daily --keep-daily 7 --keep-weekly 4
+  hostathome schedule minecraft --cron "0 */6 * * *" --mode pause --keep-last 10
+  hostathome schedule minecraft --disable`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+		flags := cmd.Flags()
+
+		if _, err := os.Stat(server.Dir(instance)); err != nil {
+			ui.Error("%s is not installed", instance)
+			return fmt.Errorf("server directory %s not found", server.Dir(instance))
+		}
+
+		manifest, err := server.LoadManifest(instance)
+		if err != nil {
+			return err
+		}
+		if manifest.Game == "" {
+			manifest.Game = gameName
+		}
+
+		if disable, _ := flags.GetBool("disable"); disable {
+			manifest.Backup = nil
+			if err := server.SaveManifest(instance, manifest); err != nil {
+				return err
+			}
+			ui.Success("Scheduled backups disabled for %s", instance)
+			return nil
+		}
+
+		changed := false
+		for _, name := range []string{"cron", "mode", "keep-last", "keep-daily", "keep-weekly"} {
+			changed = changed || flags.Changed(name)
+		}
+
+		if changed {
+			settings := manifest.Backup
+			if settings == nil {
+				settings = &server.BackupSchedule{}
+			}
+			if flags.Changed("cron") {
+				settings.Cron, _ = flags.GetString("cron")
+			}
+			if flags.Changed("mode") {
+				settings.Mode, _ = flags.GetString("mode")
+			}
+			if flags.Changed("keep-last") {
+				settings.Retention.KeepLast, _ = flags.GetInt("keep-last")
+			}
+			if flags.Changed("keep-daily") {
+				settings.Retention.KeepDaily, _ = flags.GetInt("keep-daily")
+			}
+			if flags.Changed("keep-weekly") {
+				settings.Retention.KeepWeekly, _ = flags.GetInt("keep-weekly")
+			}
+
+			if settings.Cron == "" {
+				ui.Error("A schedule is required (--cron)")
+				return fmt.Errorf("missing schedule")
+			}
+			if _, err := schedule.Parse(settings.Cron); err != nil {
+				ui.Error("%v", err)
+				return err
+			}
+			if settings.Mode != "" && settings.Mode != "pause" && settings.Mode != "stop" {
+				ui.Error("Invalid mode '%s' (expected pause or stop)", settings.Mode)
+				return fmt.Errorf("invalid mode")
+			}
+
+			manifest.Backup = settings
+			if err := server.SaveManifest(instance, manifest); err != nil {
+				return err
+			}
+			ui.Success("Backup schedule saved for %s", instance)
+			ui.Println()
+		}
+
+		if manifest.Backup == nil {
+			ui.Info("No backup schedule for %s", instance)
+			ui.Info("Set one: hostathome schedule %s --cron @daily --keep-daily 7", serverRef(gameName, instance))
+			return nil
+		}
+
+		s := manifest.Backup
+		ui.Detail("Schedule", s.Cron)
+		if sched, err := schedule.Parse(s.Cron); err == nil {
+			if next := sched.Next(time.Now()); !next.IsZero() {
+				ui.Detail("Next run", next.Format("2006-01-02 15:04"))
+			}
+		}
+		if s.Mode != "" {
+			ui.Detail("Mode", s.Mode)
+		}
+		if s.Retention.IsZero() {
+			ui.Detail("Retention", "keep all")
+		} else {
+			ui.Detail("Retention", fmt.Sprintf("last %d, daily %d, weekly %d",
+				s.Retention.KeepLast, s.Retention.KeepDaily, s.Retention.KeepWeekly))
+		}
+		ui.Println()
+		ui.Info("Backups run while 'hostathome agent' is running (or see 'hostathome agent install')")
+
+		return nil
+	},
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune <game>",
+	Short: "Delete backups not kept by the retention policy",
+	Long: `Apply the retention policy from the server's backup schedule and delete older
+backups. Safety copies left by restores are deleted too, except the newest.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		manifest, err := server.LoadManifest(instance)
+		if err != nil {
+			return err
+		}
+
+		var deleted []string
+		if manifest.Backup == nil || manifest.Backup.Retention.IsZero() {
+			ui.Info("No retention policy for %s, keeping all backups", instance)
+			ui.Info("Set one: hostathome schedule %s --keep-last 5", serverRef(gameName, instance))
+			ui.Println()
+		} else {
+			pruned, err := backup.Prune(instance, manifest.Backup.Retention, dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to prune backups: %w", err)
+			}
+			for _, b := range pruned {
+				deleted = append(deleted, b.Name)
+			}
+		}
+
+		copies, err := backup.PruneSafetyCopies(instance, dryRun)
+		if err != nil {
+			return fmt.Errorf("failed to prune safety copies: %w", err)
+		}
+		for _, c := range copies {
+			deleted = append(deleted, c.Name)
+		}
+
+		if len(deleted) == 0 {
+			ui.Success("Nothing to prune")
+			return nil
+		}
+		for _, name := range deleted {
+			if dryRun {
+				ui.Detail("Would delete", name)
+			} else {
+				ui.Detail("Deleted", name)
+			}
+		}
+		ui.Println()
+		if dryRun {
+			ui.Info("%d backup(s) would be deleted", len(deleted))
+		} else {
+			ui.Success("%d backup(s) deleted", len(deleted))
+		}
+
+		return nil
+	},
+}
+
+// createBackup archives a server instance, pausing or stopping its container
+// for the duration if requested and the container is running
+func createBackup(game *registry.Game, instance string, pause, stop bool) (*backup.Info, error) {
+	if err := docker.ValidateGameName(instance); err != nil {
+		ui.Error("Invalid instance name: %v", err)
+		return nil, fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	state, err := docker.ContainerState(instance)
+	if err != nil {
+		if pause || stop {
+			ui.Error("Cannot check container state: %v", err)
+			return nil, fmt.Errorf("failed to get container state: %w", err)
+		}
+		ui.Warning("Cannot check container state, backing up files as they are")
+	}
+	running := state == "running"
+
+	if running && stop {
+		// Let the server save its world first so the backup is consistent
+		opts := shutdownServer(game, instance, 0, false)
+		spinner := ui.NewSpinner(fmt.Sprintf("Stopping %s", serverTitle(game, instance)))
+		spinner.Start()
+		if _, err := docker.StopContainer(instance, opts); err != nil {
+			spinner.Stop(false)
+			return nil, fmt.Errorf("failed to stop container: %w", err)
+		}
+		spinner.Stop(true)
+
+		defer func() {
+			spinner := ui.NewSpinner(fmt.Sprintf("Starting %s", serverTitle(game, instance)))
+			spinner.Start()
+			if err := docker.StartContainer(instance); err != nil {
+				spinner.StopWithMessage(false, fmt.Sprintf("Failed to start %s: %v", serverTitle(game, instance), err))
+				return
+			}
+			spinner.Stop(true)
+		}()
+	} else if running && pause {
+		spinner := ui.NewSpinner(fmt.Sprintf("Pausing %s", serverTitle(game, instance)))
+		spinner.Start()
+		if err := docker.PauseContainer(instance); err != nil {
+			spinner.Stop(false)
+			return nil, fmt.Errorf("failed to pause container: %w", err)
+		}
+		spinner.Stop(true)
+
+		defer func() {
+			spinner := ui.NewSpinner(fmt.Sprintf("Resuming %s", serverTitle(game, instance)))
+			spinner.Start()
+			if err := docker.UnpauseContainer(instance); err != nil {
+				spinner.StopWithMessage(false, fmt.Sprintf("Failed to resume %s: %v", serverTitle(game, instance), err))
+				return
+			}
+			spinner.Stop(true)
+		}()
+	} else if running {
+		ui.Warning("%s is running; files may change during the backup", serverTitle(game, instance))
+		ui.Detail("Tip", "Use --pause or --stop for a consistent backup")
+	}
+
+	spinner := ui.NewSpinner("Creating backup")
+	spinner.Start()
+	info, err := backup.Create(instance, game)
+	if err != nil {
+		spinner.Stop(false)
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+	spinner.Stop(true)
+
+	return info, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the registry cache",
+	Long: `Game definitions from remote registries are cached in ~/.hostathome/cache/registry.
+Cached files are used until they are older than the cache TTL (cache_ttl in
+~/.hostathome/config.yaml, default 1h), then checked with the registry, which
+only sends files that changed.`,
+}
+
+// registryCache is a registry's cache in structured status output
+type registryCache struct {
+	Registry string `json:"registry" yaml:"registry"`
+	Source   string `json:"source" yaml:"source"`
+	// Directory is empty for local registries, which are read directly
+	Directory string                `json:"directory,omitempty" yaml:"directory,omitempty"`
+	Files     []registry.CacheEntry `json:"files" yaml:"files"`
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show cached registry files and their age",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var caches []registryCache
+		for _, reg := range registry.Registries() {
+			files, err := reg.CacheEntries()
+			if err != nil {
+				ui.Error("Failed to read cache of %s: %v", reg.Name, err)
+				return err
+			}
+			c := registryCache{Registry: reg.Name, Source: reg.Source.String(), Files: files}
+			if !reg.Source.Local() {
+				c.Directory = reg.CacheDir()
+			}
+			if c.Files == nil {
+				c.Files = []registry.CacheEntry{}
+			}
+			caches = append(caches, c)
+		}
+
+		if structured() {
+			return printData(caches)
+		}
+
+		ui.Detail("TTL", registry.CacheTTL().String())
+		for _, c := range caches {
+			ui.Println()
+			ui.Title("%s", c.Registry)
+			ui.Detail("Source", c.Source)
+			if c.Directory == "" {
+				ui.Println("   Local registry, read directly")
+				continue
+			}
+			ui.Detail("Cache", c.Directory)
+			if len(c.Files) == 0 {
+				ui.Println("   Nothing cached")
+				continue
+			}
+
+			ui.Println()
+			headers := []string{"FILE", "CHECKED", "SIZE", "STATE", "VALIDATOR"}
+			var rows [][]string
+			for _, f := range c.Files {
+				state := "stale"
+				if f.Fresh {
+					state = "fresh"
+				}
+				validator := "-"
+				switch {
+				case f.ETag != "":
+					validator = "etag " + f.ETag
+				case f.LastModified != "":
+					validator = "modified " + f.LastModified
+				}
+				rows = append(rows, []string{
+					f.Path,
+					formatAge(time.Since(f.Checked)) + " ago",
+					ui.FormatBytes(f.Size),
+					state,
+					validator,
+				})
+			}
+			ui.Table(headers, rows)
+		}
+		return nil
+	},
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh [game...]",
+	Short: "Check cached registry files for updates now",
+	Long:  "Check the cached game index and definitions with their registries regardless of age, downloading the files that changed. Give games to only refresh those.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if registry.Offline() {
+			ui.Error("Can't refresh the cache in offline mode")
+			return fmt.Errorf("offline mode")
+		}
+		registry.Revalidate()
+
+		if len(args) == 0 {
+			games, err := fetchGames()
+			if err != nil {
+				return err
+			}
+			ui.Success("Refreshed %d games", len(games))
+			return nil
+		}
+
+		for _, name := range args {
+			if _, err := registry.GetGame(name); err != nil {
+				ui.Error("Failed to refresh %s: %v", name, err)
+				return err
+			}
+			ui.Success("Refreshed %s", name)
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached registry files",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := registry.ClearCache(); err != nil {
+			ui.Error("Failed to clear cache: %v", err)
+			return err
+		}
+		ui.Success("Registry cache cleared")
+		return nil
+	},
+}
+
+// formatAge renders a duration in its largest unit, e.g. "45s", "12m", "3h" or "2d"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
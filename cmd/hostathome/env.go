@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage server environment variables",
+	Long: `Set the environment variables a game server container is created with,
+such as EULA acceptance, API keys or admin passwords.
+
+Values are stored in <game>-server/env.yaml, readable only by you, and are not
+included in backups. Changes apply when the container is next created:
+hostathome stop <game> && hostathome run <game>.`,
+}
+
+var envSetCmd = &cobra.Command{
+	Use:   "set <game> KEY=VALUE... | KEY",
+	Short: "Set environment variables for a server",
+	Long:  "Set environment variables for a server. Give only KEY to type the value without it being shown.",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+
+		game, err := registry.GetGame(gameName)
+		if err != nil {
+			reportGameError(gameName, err)
+			return err
+		}
+
+		env, err := server.LoadEnv(instance)
+		if err != nil {
+			ui.Error("Failed to read environment: %v", err)
+			return err
+		}
+
+		for _, arg := range args[1:] {
+			name, value, ok := strings.Cut(arg, "=")
+			if err := server.ValidateEnvName(name); err != nil {
+				ui.Error("%v", err)
+				return err
+			}
+			if !ok {
+				if value, err = ui.ReadSecret(fmt.Sprintf("Value for %s: ", name)); err != nil {
+					return err
+				}
+			}
+			if game.EnvVar(name) == nil {
+				ui.Warning("%s does not declare %s, setting it anyway", game.DisplayName, name)
+			}
+			env[name] = value
+		}
+
+		if err := server.SaveEnv(instance, env); err != nil {
+			ui.Error("Failed to save environment: %v", err)
+			return err
+		}
+
+		ui.Success("Environment of %s updated.", serverTitle(game, instance))
+		ui.Info("Apply with: hostathome stop %s && hostathome run %s", serverRef(gameName, instance), serverRef(gameName, instance))
+		return nil
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset <game> KEY...",
+	Short: "Remove environment variables from a server",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+
+		game, err := registry.GetGame(gameName)
+		if err != nil {
+			reportGameError(gameName, err)
+			return err
+		}
+
+		env, err := server.LoadEnv(instance)
+		if err != nil {
+			ui.Error("Failed to read environment: %v", err)
+			return err
+		}
+
+		for _, name := range args[1:] {
+			if _, ok := env[name]; !ok {
+				ui.Warning("%s is not set", name)
+			}
+			delete(env, name)
+		}
+
+		if err := server.SaveEnv(instance, env); err != nil {
+			ui.Error("Failed to save environment: %v", err)
+			return err
+		}
+
+		ui.Success("Environment of %s updated.", serverTitle(game, instance))
+		ui.Info("Apply with: hostathome stop %s && hostathome run %s", serverRef(gameName, instance), serverRef(gameName, instance))
+		return nil
+	},
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list <game>",
+	Short: "List a server's environment variables",
+	Long: `Show the variables the game declares and the values set for the server.
+Values of secret variables and of variables the game doesn't declare are
+hidden unless --show-values is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+
+		game, err := registry.GetGame(gameName)
+		if err != nil {
+			reportGameError(gameName, err)
+			return err
+		}
+
+		env, err := server.LoadEnv(instance)
+		if err != nil {
+			ui.Error("Failed to read environment: %v", err)
+			return err
+		}
+		showValues, _ := cmd.Flags().GetBool("show-values")
+		mask := func(value string) string {
+			if showValues || value == "" {
+				return value
+			}
+			return "********"
+		}
+
+		var entries []envEntry
+		for _, v := range game.Env {
+			value, set := env[v.Name]
+			source := "set"
+			if !set {
+				value = v.Default
+				source = "default"
+				if value == "" {
+					source = ""
+				}
+			}
+			if v.Secret {
+				value = mask(value)
+			}
+			entries = append(entries, envEntry{
+				Name:        v.Name,
+				Value:       value,
+				Source:      source,
+				Required:    v.Required,
+				Secret:      v.Secret,
+				Description: v.Description,
+			})
+		}
+
+		// Variables set for the server that the game doesn't declare, which may
+		// be secrets the definition doesn't know about
+		var extra []string
+		for name := range env {
+			if game.EnvVar(name) == nil {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		for _, name := range extra {
+			entries = append(entries, envEntry{Name: name, Value: mask(env[name]), Source: "set"})
+		}
+
+		if structured() {
+			if entries == nil {
+				entries = []envEntry{}
+			}
+			return printData(entries)
+		}
+
+		if len(entries) == 0 {
+			ui.Info("%s declares no environment variables", game.DisplayName)
+			return nil
+		}
+
+		ui.Title("%s Environment", serverTitle(game, instance))
+		ui.Println()
+
+		headers := []string{"NAME", "VALUE", "SOURCE", "DESCRIPTION"}
+		var rows [][]string
+		for _, e := range entries {
+			source := e.Source
+			if source == "" {
+				source = "-"
+				if e.Required {
+					source = ui.SymbolWarning + " required"
+				}
+			}
+			rows = append(rows, []string{e.Name, e.Value, source, e.Description})
+		}
+
+		ui.Table(headers, rows)
+		return nil
+	},
+}
+
+// envEntry is a server environment variable as listed by "env list".
+// Source is "set", "default" or empty if the variable has no value.
+type envEntry struct {
+	Name        string `json:"name" yaml:"name"`
+	Value       string `json:"value" yaml:"value"`
+	Source      string `json:"source" yaml:"source"`
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	Secret      bool   `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// gameInfo is the structured output of the info command
+type gameInfo struct {
+	Definition *registry.Game `json:"definition" yaml:"definition"`
+	Registry   string         `json:"registry" yaml:"registry"`
+	Shadows    []string       `json:"shadows,omitempty" yaml:"shadows,omitempty"`
+	// Image is nil if the image hasn't been pulled or Docker is unavailable
+	Image   *docker.ImageInfo `json:"image" yaml:"image"`
+	Servers []localServer     `json:"servers" yaml:"servers"`
+}
+
+// localServer is an instance of a game on this machine
+type localServer struct {
+	Instance string `json:"instance" yaml:"instance"`
+	// Directory is the server's data directory, if it exists
+	Directory string `json:"directory,omitempty" yaml:"directory,omitempty"`
+	// Container is the container state, if a container exists
+	Container string `json:"container,omitempty" yaml:"container,omitempty"`
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info <game>",
+	Short: "Show details of a game",
+	Long: `Show everything the game definition declares, whether its image has been
+pulled, and which servers of the game exist on this machine.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+
+		game, err := registry.GetGame(gameName)
+		if err != nil {
+			reportGameError(gameName, err)
+			ui.Info("Run 'hostathome list' to see available games")
+			return err
+		}
+
+		info := &gameInfo{Definition: game, Registry: game.Registry, Shadows: game.Shadows}
+
+		dockerOK := true
+		info.Image, err = docker.InspectImage(game.Image)
+		if err != nil {
+			ui.Warning("Cannot inspect image (is Docker running?): %v", err)
+			dockerOK = false
+		}
+
+		info.Servers, err = localServers(game.Name, dockerOK)
+		if err != nil {
+			ui.Error("Failed to find servers: %v", err)
+			return err
+		}
+
+		if structured() {
+			return printData(info)
+		}
+
+		printGameInfo(info, dockerOK)
+		return nil
+	},
+}
+
+// localServers finds the instances of a game that have a server directory or a
+// container. Containers are only looked up if withContainers is set.
+func localServers(gameName string, withContainers bool) ([]localServer, error) {
+	servers := []localServer{}
+	index := make(map[string]int)
+	add := func(instance string) *localServer {
+		if i, ok := index[instance]; ok {
+			return &servers[i]
+		}
+		index[instance] = len(servers)
+		servers = append(servers, localServer{Instance: instance})
+		return &servers[len(servers)-1]
+	}
+
+	// The default instance has no manifest until it has been run
+	if fi, err := os.Stat(server.Dir(gameName)); err == nil && fi.IsDir() {
+		add(gameName).Directory = server.Dir(gameName)
+	}
+
+	instances, err := server.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range instances {
+		manifest, err := server.LoadManifest(instance)
+		if err != nil || manifest.Game != gameName {
+			continue
+		}
+		add(instance).Directory = server.Dir(instance)
+	}
+
+	if withContainers {
+		statuses, err := docker.GetStatus(gameName)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range statuses {
+			add(s.Instance).Container = s.Status
+		}
+	}
+
+	return servers, nil
+}
+
+// printGameInfo renders game details for humans. Without Docker the image and
+// containers are shown as unknown.
+func printGameInfo(info *gameInfo, dockerOK bool) {
+	game := info.Definition
+
+	ui.Title("%s", game.DisplayName)
+	if game.Description != "" {
+		ui.Println(game.Description)
+	}
+	ui.Println()
+
+	source := info.Registry
+	if len(info.Shadows) > 0 {
+		source += " (overrides " + strings.Join(info.Shadows, ", ") + ")"
+	}
+	ui.Detail("Name", game.Name)
+	ui.Detail("Registry", source)
+	ui.Detail("Image", game.Image)
+	if len(game.Tags) > 0 {
+		ui.Detail("Tags", strings.Join(game.Tags, ", "))
+	}
+	for _, v := range game.Volumes {
+		ui.Detail("Volume", v)
+	}
+
+	if len(game.Ports) > 0 {
+		ui.Println()
+		headers := []string{"PORT", "HOST", "CONTAINER", "PROTOCOL"}
+		var rows [][]string
+		for _, p := range game.Ports {
+			container := fmt.Sprintf("%d", p.Internal)
+			host := fmt.Sprintf("%d", p.Host)
+			if p.Count() > 1 {
+				container = fmt.Sprintf("%d-%d", p.Internal, p.Internal+p.Count()-1)
+				host = fmt.Sprintf("%d-%d", p.Host, p.Host+p.Count()-1)
+			}
+			rows = append(rows, []string{p.Name, host, container, p.Proto()})
+		}
+		ui.Table(headers, rows)
+	}
+
+	ui.Println()
+	ui.Title("Features")
+	if game.Query != nil {
+		ui.Detail("Query", fmt.Sprintf("%s on %s port", game.Query.Protocol, game.Query.PortName()))
+	}
+	if s := game.Shutdown; s != nil && len(s.Commands) > 0 {
+		ui.Detail("Shutdown", strings.Join(s.Commands, ", "))
+	}
+	if r := game.Ready; r != nil && r.Log != "" {
+		ui.Detail("Ready", fmt.Sprintf("log matches %q", r.Log))
+	}
+	if h := game.Healthcheck; h != nil {
+		if h.Port != "" {
+			ui.Detail("Healthcheck", h.Port+" port accepts connections")
+		} else {
+			ui.Detail("Healthcheck", h.Command)
+		}
+	}
+	if game.TTY() {
+		ui.Detail("Console", "terminal (TTY)")
+	}
+	if r := game.Resources; r != nil {
+		ui.Detail("Resources", formatResources(r))
+	}
+	for _, v := range game.Env {
+		label := v.Name
+		if v.Required {
+			label += " (required)"
+		}
+		ui.Detail("Env", strings.TrimSpace(label+" "+v.Description))
+	}
+	if game.Query == nil && game.Shutdown == nil && game.Ready == nil && game.Healthcheck == nil &&
+		!game.TTY() && game.Resources == nil && len(game.Env) == 0 {
+		ui.Println("   none")
+	}
+
+	ui.Println()
+	ui.Title("Image")
+	if img := info.Image; img != nil {
+		id := strings.TrimPrefix(img.ID, "sha256:")
+		ui.Detail("ID", id[:min(12, len(id))])
+		if img.Digest != "" {
+			ui.Detail("Digest", img.Digest)
+		}
+		ui.Detail("Size", ui.FormatBytes(img.Size))
+		if !img.Created.IsZero() {
+			ui.Detail("Created", img.Created.Local().Format("2006-01-02 15:04:05"))
+		}
+	} else if dockerOK {
+		ui.Println("   not pulled")
+	} else {
+		ui.Println("   unknown")
+	}
+
+	ui.Println()
+	ui.Title("Servers")
+	if len(info.Servers) == 0 {
+		ui.Println("   none")
+		ui.Println()
+		ui.Info("Install: hostathome install %s", game.Name)
+		return
+	}
+	for _, s := range info.Servers {
+		var parts []string
+		if s.Directory != "" {
+			parts = append(parts, s.Directory+"/")
+		} else {
+			parts = append(parts, "no directory")
+		}
+		switch {
+		case s.Container != "":
+			parts = append(parts, "container "+s.Container)
+		case dockerOK:
+			parts = append(parts, "no container")
+		}
+		ui.Detail(s.Instance, strings.Join(parts, ", "))
+	}
+}
+
+// formatResources summarizes recommended limits, e.g. "memory 4g, 2 CPUs"
+func formatResources(r *registry.Resources) string {
+	var parts []string
+	if r.Memory != "" {
+		parts = append(parts, "memory "+r.Memory)
+	}
+	if r.Swap != "" {
+		parts = append(parts, "swap "+r.Swap)
+	}
+	if r.CPUs != nil && *r.CPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%g CPUs", *r.CPUs))
+	}
+	if r.CPUShares != nil && *r.CPUShares > 0 {
+		parts = append(parts, fmt.Sprintf("%d CPU shares", *r.CPUShares))
+	}
+	if r.PIDs != nil && *r.PIDs > 0 {
+		parts = append(parts, fmt.Sprintf("%d processes", *r.PIDs))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,14 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/hostathome/cli/internal/backup"
+	"github.com/hostathome/cli/internal/config"
 	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/minisign"
 	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
 	"github.com/hostathome/cli/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -29,6 +37,120 @@ var rootCmd = &cobra.Command{
 	Version:       cliVersion,
 	SilenceErrors: true,
 	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupOutput(); err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+		return configureRegistry()
+	},
+}
+
+var (
+	registryFlags []string
+	// offlineMode uses only cached game definitions and local images
+	offlineMode bool
+)
+
+// configureRegistry sets up the registries from the --registry flags, the
+// HOSTATHOME_REGISTRY env var or the config file, in that order, and applies
+// the configured cache TTL and offline mode
+func configureRegistry() error {
+	cfg, err := config.Load()
+	if err != nil {
+		ui.Error("%v", err)
+		return err
+	}
+	offlineMode = offlineMode || cfg.Offline
+	registry.SetOffline(offlineMode)
+	if cfg.VerifySignatures {
+		if err := requireSignatures(); err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+	}
+	ttl, ok, err := cfg.CacheTTLDuration()
+	if err != nil {
+		ui.Error("%v", err)
+		return err
+	}
+	if ok {
+		registry.SetCacheTTL(ttl)
+	}
+
+	var entries []config.RegistryEntry
+	for _, value := range registryFlags {
+		entries = append(entries, config.ParseRegistryEntry(value))
+	}
+	if len(entries) == 0 {
+		if env := os.Getenv(config.RegistryEnv); env != "" {
+			for _, value := range strings.Split(env, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					entries = append(entries, config.ParseRegistryEntry(value))
+				}
+			}
+		}
+	}
+	if len(entries) == 0 {
+		entries = cfg.RegistryEntries()
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := registry.Configure(entries); err != nil {
+		ui.Error("Invalid registry configuration: %v", err)
+		return err
+	}
+	return nil
+}
+
+// requireSignatures loads the trusted keys and makes the registry refuse
+// unsigned or mis-signed remote files
+func requireSignatures() error {
+	dir, err := config.TrustedKeysDir()
+	if err != nil {
+		return err
+	}
+	keys, err := minisign.LoadKeys(dir)
+	if err != nil {
+		return fmt.Errorf("failed to load trusted keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("signature verification is enabled but %s has no trusted keys (*.pub)", dir)
+	}
+	registry.RequireSignatures(keys)
+	return nil
+}
+
+// Doctor check outcomes
+const (
+	checkOK      = "ok"
+	checkWarning = "warning"
+	checkError   = "error"
+)
+
+// doctorCheck is the result of one doctor check
+type doctorCheck struct {
+	Name    string `json:"name" yaml:"name"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+	Fix     string `json:"fix,omitempty" yaml:"fix,omitempty"`
+	Note    string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// doctorReport is the structured output of the doctor command
+type doctorReport struct {
+	Ready  bool          `json:"ready" yaml:"ready"`
+	Checks []doctorCheck `json:"checks" yaml:"checks"`
+	// Registries are the configured registries, highest priority first
+	Registries []registrySource `json:"registries" yaml:"registries"`
+}
+
+// registrySource is a configured registry in structured output
+type registrySource struct {
+	Name   string `json:"name" yaml:"name"`
+	Source string `json:"source" yaml:"source"`
 }
 
 var doctorCmd = &cobra.Command{
@@ -36,69 +158,46 @@ var doctorCmd = &cobra.Command{
 	Short: "Check system requirements",
 	Long:  "Verify that Docker is installed and running, and check system readiness.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ui.Title("HostAtHome Doctor")
-		fmt.Println()
+		report := runDoctorChecks()
 
-		allGood := true
-
-		// Check Docker installed
-		ui.Step("Checking Docker installation...")
-		_, err := exec.LookPath("docker")
-		if err != nil {
-			ui.Error("Docker not found in PATH")
-			ui.Detail("Fix", "Install Docker: https://docs.docker.com/get-docker/")
-			allGood = false
-		} else {
-			ui.Success("Docker is installed")
-		}
-
-		// Check Docker daemon running
-		ui.Step("Checking Docker daemon...")
-		cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-		if err != nil {
-			ui.Error("Cannot connect to Docker: %v", err)
-			allGood = false
-		} else {
-			defer cli.Close()
-			_, err = cli.Ping(context.Background())
-			if err != nil {
-				ui.Error("Docker daemon not running")
-				ui.Detail("Fix", "Start Docker: sudo systemctl start docker")
-				allGood = false
-			} else {
-				ui.Success("Docker daemon is running")
+		if structured() {
+			if err := printData(report); err != nil {
+				return err
+			}
+			if !report.Ready {
+				return fmt.Errorf("system not ready")
 			}
+			return nil
 		}
 
-		// Check Docker permissions
-		ui.Step("Checking Docker permissions...")
-		ctx := context.Background()
-		if cli != nil {
-			_, err = cli.ImageList(ctx, image.ListOptions{})
-			if err != nil {
-				ui.Error("Cannot access Docker (permission denied?)")
-				ui.Detail("Fix", "Add user to docker group: sudo usermod -aG docker $USER")
-				ui.Detail("Note", "Log out and back in after adding to group")
-				allGood = false
-			} else {
-				ui.Success("Docker permissions OK")
+		ui.Title("HostAtHome Doctor")
+		ui.Println()
+
+		for _, c := range report.Checks {
+			ui.Step("Checking %s...", c.Name)
+			switch c.Status {
+			case checkOK:
+				ui.Success("%s", c.Message)
+			case checkWarning:
+				ui.Warning("%s", c.Message)
+			default:
+				ui.Error("%s", c.Message)
+			}
+			if c.Fix != "" {
+				ui.Detail("Fix", c.Fix)
+			}
+			if c.Note != "" {
+				ui.Detail("Note", c.Note)
 			}
 		}
-
-		// Check registry access
-		ui.Step("Checking registry access...")
-		_, err = registry.ListGames()
-		if err != nil {
-			ui.Warning("Cannot fetch game registry (offline?)")
-			ui.Detail("Note", "CLI will use cached data if available")
-		} else {
-			ui.Success("Registry accessible")
+		for _, reg := range report.Registries {
+			ui.Detail(reg.Name, reg.Source)
 		}
 
-		fmt.Println()
-		if allGood {
+		ui.Println()
+		if report.Ready {
 			ui.Success("All checks passed! You're ready to go.")
-			fmt.Println()
+			ui.Println()
 			ui.Info("Try: hostathome list")
 		} else {
 			ui.Error("Some checks failed. Please fix the issues above.")
@@ -108,6 +207,125 @@ var doctorCmd = &cobra.Command{
 	},
 }
 
+// runDoctorChecks checks Docker and registry access. The system is ready
+// unless a check fails with an error.
+func runDoctorChecks() *doctorReport {
+	report := &doctorReport{Ready: true, Registries: []registrySource{}}
+	add := func(c doctorCheck) {
+		if c.Status == checkError {
+			report.Ready = false
+		}
+		report.Checks = append(report.Checks, c)
+	}
+
+	// Check Docker installed
+	if _, err := exec.LookPath("docker"); err != nil {
+		add(doctorCheck{Name: "Docker installation", Status: checkError, Message: "Docker not found in PATH",
+			Fix: "Install Docker: https://docs.docker.com/get-docker/"})
+	} else {
+		add(doctorCheck{Name: "Docker installation", Status: checkOK, Message: "Docker is installed"})
+	}
+
+	// Check Docker daemon running
+	dockerOK := false
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		add(doctorCheck{Name: "Docker daemon", Status: checkError, Message: fmt.Sprintf("Cannot connect to Docker: %v", err)})
+	} else {
+		defer cli.Close()
+		if _, err := cli.Ping(context.Background()); err != nil {
+			add(doctorCheck{Name: "Docker daemon", Status: checkError, Message: "Docker daemon not running",
+				Fix: "Start Docker: sudo systemctl start docker"})
+		} else {
+			add(doctorCheck{Name: "Docker daemon", Status: checkOK, Message: "Docker daemon is running"})
+		}
+
+		// Check Docker permissions
+		if _, err := cli.ImageList(context.Background(), image.ListOptions{}); err != nil {
+			add(doctorCheck{Name: "Docker permissions", Status: checkError, Message: "Cannot access Docker (permission denied?)",
+				Fix: "Add user to docker group: sudo usermod -aG docker $USER", Note: "Log out and back in after adding to group"})
+		} else {
+			add(doctorCheck{Name: "Docker permissions", Status: checkOK, Message: "Docker permissions OK"})
+			dockerOK = true
+		}
+	}
+
+	if registry.VerifyingSignatures() {
+		add(doctorCheck{Name: "registry signatures", Status: checkOK, Message: "Remote registry files must be signed by a trusted key"})
+	}
+
+	// Check registry access
+	var partial *registry.PartialError
+	if offlineMode {
+		add(doctorCheck{Name: "registry access", Status: checkOK, Message: "Offline mode, using cached game definitions"})
+		checks, err := offlineChecks(cli, dockerOK)
+		if err != nil {
+			add(doctorCheck{Name: "offline readiness", Status: checkError, Message: fmt.Sprintf("Cannot list servers: %v", err)})
+		}
+		for _, c := range checks {
+			add(c)
+		}
+	} else if _, err := registry.ListGames(); errors.As(err, &partial) {
+		add(doctorCheck{Name: "registry access", Status: checkWarning, Message: "Some games could not be loaded",
+			Note: partial.Error()})
+	} else if err != nil {
+		add(doctorCheck{Name: "registry access", Status: checkWarning, Message: "Cannot fetch game registry (offline?)",
+			Note: "CLI will use cached data if available"})
+	} else {
+		add(doctorCheck{Name: "registry access", Status: checkOK, Message: "Registry accessible"})
+	}
+	for _, reg := range registry.Registries() {
+		report.Registries = append(report.Registries, registrySource{Name: reg.Name, Source: reg.Source.String()})
+	}
+
+	return report
+}
+
+// offlineChecks checks that every server in the current directory can be run
+// offline: its game definition must be cached and its image pulled. Images are
+// only checked if Docker is usable.
+func offlineChecks(cli *client.Client, dockerOK bool) ([]doctorCheck, error) {
+	installed, err := server.Installed()
+	if err != nil {
+		return nil, err
+	}
+	if len(installed) == 0 {
+		return []doctorCheck{{Name: "offline readiness", Status: checkWarning, Message: "No servers installed in this directory"}}, nil
+	}
+
+	instances := make([]string, 0, len(installed))
+	for instance := range installed {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+
+	var checks []doctorCheck
+	for _, instance := range instances {
+		gameName := installed[instance]
+		name := "offline readiness of " + instance
+		game, err := registry.GetGame(gameName)
+		if err != nil {
+			checks = append(checks, doctorCheck{Name: name, Status: checkError,
+				Message: fmt.Sprintf("Game definition of %s is not cached", gameName),
+				Fix:     fmt.Sprintf("While online, run: hostathome cache refresh %s", gameName)})
+			continue
+		}
+		if !dockerOK {
+			checks = append(checks, doctorCheck{Name: name, Status: checkWarning,
+				Message: "Game definition is cached; image not checked without Docker"})
+			continue
+		}
+		if _, _, err := cli.ImageInspectWithRaw(context.Background(), game.Image); err != nil {
+			checks = append(checks, doctorCheck{Name: name, Status: checkError,
+				Message: fmt.Sprintf("Image %s has not been pulled", game.Image),
+				Fix:     fmt.Sprintf("While online, run: docker pull %s", game.Image)})
+			continue
+		}
+		checks = append(checks, doctorCheck{Name: name, Status: checkOK, Message: "Game definition and image are available offline"})
+	}
+	return checks, nil
+}
+
 var installCmd = &cobra.Command{
 	Use:   "install <game>",
 	Short: "Install a game server",
@@ -115,57 +333,251 @@ var installCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
 
 		game, err := registry.GetGame(gameName)
 		if err != nil {
-			ui.Error("Game '%s' not found", gameName)
+			reportGameError(gameName, err)
 			ui.Info("Run 'hostathome list' to see available games")
 			return err
 		}
 
-		ui.Title("Installing %s", game.DisplayName)
-		fmt.Println()
+		ui.Title("Installing %s", serverTitle(game, instance))
+		ui.Println()
 
 		// Pull Docker image
-		spinner := ui.NewSpinner(fmt.Sprintf("Pulling %s", game.Image))
-		spinner.Start()
-		if err := docker.PullImage(game.Image); err != nil {
-			spinner.Stop(false)
-			return fmt.Errorf("failed to pull image: %w", err)
+		if offlineMode {
+			img, err := docker.InspectImage(game.Image)
+			if err != nil {
+				ui.Error("Failed to inspect image: %v", err)
+				return err
+			}
+			if img == nil {
+				ui.Error("Image %s has not been pulled and can't be in offline mode", game.Image)
+				return fmt.Errorf("image %s not available offline", game.Image)
+			}
+			ui.Success("Using local image %s (offline)", game.Image)
+		} else {
+			spinner := ui.NewSpinner(fmt.Sprintf("Pulling %s", game.Image))
+			spinner.Start()
+			if err := docker.PullImage(game.Image); err != nil {
+				spinner.Stop(false)
+				return fmt.Errorf("failed to pull image: %w", err)
+			}
+			spinner.Stop(true)
 		}
-		spinner.Stop(true)
 
 		// Create directory structure
-		spinner = ui.NewSpinner("Creating directory structure")
+		spinner := ui.NewSpinner("Creating directory structure")
 		spinner.Start()
-		if err := docker.CreateServerDirs(gameName); err != nil {
+		if err := docker.CreateServerDirs(instance); err != nil {
 			spinner.Stop(false)
 			return fmt.Errorf("failed to create directories: %w", err)
 		}
+		// Record the game, so the instance isn't later run as another one
+		if err := recordGame(instance, game); err != nil {
+			spinner.Stop(false)
+			return fmt.Errorf("failed to write server manifest: %w", err)
+		}
 		spinner.Stop(true)
 
 		// Copy default config
 		spinner = ui.NewSpinner("Writing default configuration")
 		spinner.Start()
-		if err := registry.CopyDefaultConfig(gameName, game); err != nil {
+		if err := registry.CopyDefaultConfig(instance, game); err != nil {
 			spinner.Stop(false)
 			return fmt.Errorf("failed to copy default config: %w", err)
 		}
 		spinner.Stop(true)
 
-		fmt.Println()
-		ui.Success("%s installed successfully!", game.DisplayName)
-		fmt.Println()
-		ui.Detail("Directory", fmt.Sprintf("./%s-server/", gameName))
-		ui.Detail("Config", fmt.Sprintf("./%s-server/configs/config.yaml", gameName))
-		fmt.Println()
-		ui.Info("Start with: hostathome run %s", gameName)
+		ui.Println()
+		ui.Success("%s installed successfully!", serverTitle(game, instance))
+		ui.Println()
+		ui.Detail("Directory", server.Dir(instance)+"/")
+		ui.Detail("Config", server.Dir(instance)+"/configs/config.yaml")
+		ui.Println()
+		ui.Info("Start with: hostathome run %s", serverRef(gameName, instance))
 
 		return nil
 	},
 }
 
-var devMode bool
+var (
+	devMode      bool
+	autoPorts    bool
+	portFlags    []string
+	instanceName string
+)
+
+// portLabel returns the display label for a named game port, e.g. "Player port"
+func portLabel(name string) string {
+	if name == "rcon" {
+		return "RCON port"
+	}
+	return strings.ToUpper(name[:1]) + name[1:] + " port"
+}
+
+// savePortOverrides parses name=port values and pins them in the server manifest
+func savePortOverrides(instance string, game *registry.Game, values []string) error {
+	manifest, err := server.LoadManifest(instance)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		name, portStr, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("expected name=port, got '%s'", value)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return fmt.Errorf("invalid port number '%s'", portStr)
+		}
+		if game.Port(name) == nil {
+			return fmt.Errorf("%s has no port named '%s'", game.DisplayName, name)
+		}
+		if err := docker.ValidatePort(port, name); err != nil {
+			return err
+		}
+		manifest.SetPort(name, port)
+	}
+
+	return server.SaveManifest(instance, manifest)
+}
+
+// saveResourceOverrides pins the resource limits given on the command line in the server manifest
+func saveResourceOverrides(cmd *cobra.Command, instance string, game *registry.Game) error {
+	flags := cmd.Flags()
+	if !flags.Changed("memory") && !flags.Changed("swap") && !flags.Changed("cpus") &&
+		!flags.Changed("cpu-shares") && !flags.Changed("pids-limit") {
+		return nil
+	}
+
+	manifest, err := server.LoadManifest(instance)
+	if err != nil {
+		return err
+	}
+
+	// Only flags given are saved, 0 removes a limit
+	var override registry.Resources
+	override.Memory, _ = flags.GetString("memory")
+	override.Swap, _ = flags.GetString("swap")
+	if flags.Changed("cpus") {
+		cpus, _ := flags.GetFloat64("cpus")
+		override.CPUs = &cpus
+	}
+	if flags.Changed("cpu-shares") {
+		shares, _ := flags.GetInt64("cpu-shares")
+		override.CPUShares = &shares
+	}
+	if flags.Changed("pids-limit") {
+		pids, _ := flags.GetInt64("pids-limit")
+		override.PIDs = &pids
+	}
+
+	var current registry.Resources
+	if manifest.Resources != nil {
+		current = *manifest.Resources
+	}
+	merged := current.Merge(&override)
+
+	var limits registry.Resources
+	if game.Resources != nil {
+		limits = *game.Resources
+	}
+	if err := docker.ValidateResources(limits.Merge(&merged)); err != nil {
+		return err
+	}
+
+	manifest.Resources = &merged
+	return server.SaveManifest(instance, manifest)
+}
+
+// formatLimits renders a container's resource limits for status output
+func formatLimits(s docker.ContainerStatus) string {
+	var parts []string
+	if s.MemoryLimit > 0 {
+		parts = append(parts, ui.FormatBytes(s.MemoryLimit))
+	}
+	if s.CPULimit > 0 {
+		parts = append(parts, strconv.FormatFloat(s.CPULimit, 'f', -1, 64)+" CPU")
+	}
+	if s.PidsLimit > 0 {
+		parts = append(parts, fmt.Sprintf("%d PIDs", s.PidsLimit))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// instanceFor returns the server instance to operate on, defaulting to the game
+// name. It refuses invalid instance names and instances set up for another game.
+func instanceFor(gameName string) (string, error) {
+	instance := gameName
+	if instanceName != "" {
+		instance = instanceName
+	}
+	if err := docker.ValidateGameName(instance); err != nil {
+		return "", fmt.Errorf("invalid instance name: %w", err)
+	}
+	if other := instanceGame(instance); other != "" && other != gameName {
+		return "", fmt.Errorf("server '%s' runs %s, not %s", instance, other, gameName)
+	}
+	return instance, nil
+}
+
+// instanceGame returns the game a server instance was set up for, from its
+// manifest or else its container, or "" if neither records one
+func instanceGame(instance string) string {
+	if manifest, err := server.LoadManifest(instance); err == nil && manifest.Game != "" {
+		return manifest.Game
+	}
+	game, _ := docker.ContainerGame(instance)
+	return game
+}
+
+// recordGame saves the game of a server instance in its manifest
+func recordGame(instance string, game *registry.Game) error {
+	manifest, err := server.LoadManifest(instance)
+	if err != nil {
+		return err
+	}
+	if manifest.Game == game.Name {
+		return nil
+	}
+	manifest.Game = game.Name
+	return server.SaveManifest(instance, manifest)
+}
+
+// reportGameError explains why a game definition couldn't be loaded
+func reportGameError(gameName string, err error) {
+	if errors.Is(err, registry.ErrNotFound) {
+		ui.Error("Game '%s' not found", gameName)
+		return
+	}
+	ui.Error("Cannot load %v", err)
+}
+
+// serverTitle returns the display name for a server instance
+func serverTitle(game *registry.Game, instance string) string {
+	if instance == game.Name {
+		return game.DisplayName
+	}
+	return fmt.Sprintf("%s (%s)", game.DisplayName, instance)
+}
+
+// serverRef returns the command arguments that select a server instance
+func serverRef(gameName, instance string) string {
+	if instance == gameName {
+		return gameName
+	}
+	return gameName + " --name " + instance
+}
 
 var runCmd = &cobra.Command{
 	Use:   "run <game>",
@@ -174,35 +586,30 @@ var runCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
 
 		var game *registry.Game
-		var err error
-
 		if devMode {
 			// Dev mode: use local :dev image
 			game = &registry.Game{
 				Name:        gameName,
 				DisplayName: gameName + " (dev)",
 				Image:       gameName + "-server:dev",
-				Ports: registry.Ports{
-					Player: 30065,
-					RCON:   30066,
-				},
-				InternalPorts: registry.Ports{
-					Player: 25565,
-					RCON:   25575,
-				},
-				Protocols: registry.Protocols{
-					Player: "tcp",
-					RCON:   "tcp",
+				Ports: registry.PortList{
+					{Name: "player", Protocol: "tcp", Internal: 25565, Host: 30065},
+					{Name: "rcon", Protocol: "tcp", Internal: 25575, Host: 30066},
 				},
 			}
-			fmt.Println("🔧 Development mode: using local image", game.Image)
+			ui.Println("🔧 Development mode: using local image", game.Image)
 		} else {
 			// Normal mode: fetch from registry
 			game, err = registry.GetGame(gameName)
 			if err != nil {
-				ui.Error("Game '%s' not found", gameName)
+				reportGameError(gameName, err)
 				return err
 			}
 		}
@@ -210,32 +617,62 @@ var runCmd = &cobra.Command{
 		// Create directory structure if it doesn't exist
 		spinner := ui.NewSpinner("Creating directory structure")
 		spinner.Start()
-		if err := docker.CreateServerDirs(gameName); err != nil {
+		if err := docker.CreateServerDirs(instance); err != nil {
 			spinner.Stop(false)
 			return fmt.Errorf("failed to create directories: %w", err)
 		}
 		spinner.Stop(true)
 
-		spinner = ui.NewSpinner(fmt.Sprintf("Starting %s", game.DisplayName))
+		// Save port overrides so they survive remove/run cycles
+		if len(portFlags) > 0 {
+			if err := savePortOverrides(instance, game, portFlags); err != nil {
+				ui.Error("Invalid port override: %v", err)
+				return err
+			}
+		}
+
+		if err := saveResourceOverrides(cmd, instance, game); err != nil {
+			ui.Error("Invalid resource limit: %v", err)
+			return err
+		}
+
+		spinner = ui.NewSpinner(fmt.Sprintf("Starting %s", serverTitle(game, instance)))
 		spinner.Start()
 
-		if err := docker.RunContainer(gameName, game, devMode); err != nil {
+		ports, err := docker.RunContainer(instance, game, docker.RunOptions{
+			DevMode:   devMode,
+			AutoPorts: autoPorts,
+			Offline:   offlineMode,
+		})
+		if err != nil {
 			spinner.Stop(false)
 			ui.Error("Failed to start container: %v", err)
+			var missing *docker.MissingEnvError
+			if errors.As(err, &missing) {
+				ui.Info("Set with: hostathome env set %s %s=<value>", serverRef(gameName, instance), missing.Names[0])
+			}
 			return fmt.Errorf("failed to start container: %w", err)
 		}
 		spinner.Stop(true)
 
-		fmt.Println()
-		ui.Success("%s is running!", game.DisplayName)
-		fmt.Println()
-		ui.Detail("Player port", fmt.Sprintf("%d", game.Ports.Player))
-		if game.Ports.RCON > 0 {
-			ui.Detail("RCON port", fmt.Sprintf("%d", game.Ports.RCON))
+		if wait, _ := cmd.Flags().GetBool("wait"); wait {
+			timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+			if err := waitForServer(game, instance, timeout); err != nil {
+				return err
+			}
 		}
-		fmt.Println()
-		ui.Info("View logs: hostathome logs %s", gameName)
-		ui.Info("Stop: hostathome stop %s", gameName)
+
+		ui.Println()
+		ui.Success("%s is running!", serverTitle(game, instance))
+		ui.Println()
+		for _, p := range ports {
+			if p.Host > 0 {
+				ui.Detail(portLabel(p.Name), p.String())
+			}
+		}
+		ui.Println()
+		ui.Info("View logs: hostathome logs %s", serverRef(gameName, instance))
+		ui.Info("Stop: hostathome stop %s", serverRef(gameName, instance))
 
 		return nil
 	},
@@ -248,24 +685,35 @@ var stopCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
 
 		game, err := registry.GetGame(gameName)
 		if err != nil {
-			ui.Error("Game '%s' not found", gameName)
+			reportGameError(gameName, err)
 			return err
 		}
 
-		spinner := ui.NewSpinner(fmt.Sprintf("Stopping %s", game.DisplayName))
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		noWarn, _ := cmd.Flags().GetBool("no-warn")
+		opts := shutdownServer(game, instance, timeout, !noWarn)
+
+		spinner := ui.NewSpinner(fmt.Sprintf("Stopping %s", serverTitle(game, instance)))
 		spinner.Start()
 
-		if err := docker.StopContainer(gameName); err != nil {
+		result, err := docker.StopContainer(instance, opts)
+		if err != nil {
 			spinner.Stop(false)
 			return fmt.Errorf("failed to stop container: %w", err)
 		}
 		spinner.Stop(true)
 
-		fmt.Println()
-		ui.Success("%s stopped.", game.DisplayName)
+		ui.Println()
+		ui.Success("%s stopped.", serverTitle(game, instance))
+		reportStop(result)
 
 		return nil
 	},
@@ -278,24 +726,42 @@ var restartCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
 
 		game, err := registry.GetGame(gameName)
 		if err != nil {
-			ui.Error("Game '%s' not found", gameName)
+			reportGameError(gameName, err)
 			return err
 		}
 
-		spinner := ui.NewSpinner(fmt.Sprintf("Restarting %s", game.DisplayName))
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		noWarn, _ := cmd.Flags().GetBool("no-warn")
+		opts := shutdownServer(game, instance, timeout, !noWarn)
+
+		spinner := ui.NewSpinner(fmt.Sprintf("Restarting %s", serverTitle(game, instance)))
 		spinner.Start()
 
-		if err := docker.RestartContainer(gameName); err != nil {
+		result, err := docker.RestartContainer(instance, opts)
+		if err != nil {
 			spinner.Stop(false)
 			return fmt.Errorf("failed to restart container: %w", err)
 		}
 		spinner.Stop(true)
+		reportStop(result)
+
+		if wait, _ := cmd.Flags().GetBool("wait"); wait {
+			timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+			if err := waitForServer(game, instance, timeout); err != nil {
+				return err
+			}
+		}
 
-		fmt.Println()
-		ui.Success("%s restarted.", game.DisplayName)
+		ui.Println()
+		ui.Success("%s restarted.", serverTitle(game, instance))
 		ui.Info("Configuration changes have been applied")
 
 		return nil
@@ -309,27 +775,38 @@ var removeCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
 
 		game, err := registry.GetGame(gameName)
 		if err != nil {
-			ui.Error("Game '%s' not found", gameName)
+			reportGameError(gameName, err)
 			return err
 		}
 
-		spinner := ui.NewSpinner(fmt.Sprintf("Removing %s container", game.DisplayName))
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		noWarn, _ := cmd.Flags().GetBool("no-warn")
+		opts := shutdownServer(game, instance, timeout, !noWarn)
+
+		spinner := ui.NewSpinner(fmt.Sprintf("Removing %s container", serverTitle(game, instance)))
 		spinner.Start()
 
-		if err := docker.RemoveContainer(gameName); err != nil {
+		result, err := docker.RemoveContainer(instance, opts)
+		if err != nil {
 			spinner.Stop(false)
 			return fmt.Errorf("failed to remove container: %w", err)
 		}
 		spinner.Stop(true)
 
-		fmt.Println()
-		ui.Success("%s container removed.", game.DisplayName)
-		fmt.Println()
-		ui.Detail("Data preserved", fmt.Sprintf("./%s-server/", gameName))
-		ui.Info("Run 'hostathome run %s' to recreate the container", gameName)
+		ui.Println()
+		ui.Success("%s container removed.", serverTitle(game, instance))
+		reportStop(result)
+		ui.Println()
+		ui.Detail("Data preserved", server.Dir(instance)+"/")
+		ui.Info("Run 'hostathome run %s' to recreate the container", serverRef(gameName, instance))
 
 		return nil
 	},
@@ -342,19 +819,24 @@ var uninstallCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
 
 		game, err := registry.GetGame(gameName)
 		if err != nil {
-			ui.Error("Game '%s' not found", gameName)
+			reportGameError(gameName, err)
 			return err
 		}
 
 		// Confirm before deleting data
-		fmt.Println()
-		ui.Warning("This will permanently delete all data for %s", game.DisplayName)
-		ui.Detail("Directory", fmt.Sprintf("./%s-server/", gameName))
-		fmt.Println()
-		fmt.Print("Are you sure? (yes/no): ")
+		ui.Println()
+		ui.Warning("This will permanently delete all data for %s", serverTitle(game, instance))
+		ui.Detail("Directory", server.Dir(instance)+"/")
+		ui.Println()
+		ui.Printf("Are you sure? (yes/no): ")
 
 		var response string
 		fmt.Scanln(&response)
@@ -364,19 +846,21 @@ var uninstallCmd = &cobra.Command{
 		}
 
 		// Remove container
-		spinner := ui.NewSpinner(fmt.Sprintf("Removing %s container", game.DisplayName))
+		spinner := ui.NewSpinner(fmt.Sprintf("Removing %s container", serverTitle(game, instance)))
 		spinner.Start()
-		if err := docker.RemoveContainer(gameName); err != nil {
+		if _, err := docker.RemoveContainer(instance, docker.StopOptions{}); err != nil {
 			// Container might not exist, that's ok
-			spinner.StopWithMessage(true, fmt.Sprintf("No container found for %s", game.DisplayName))
+			spinner.StopWithMessage(true, fmt.Sprintf("No container found for %s", serverTitle(game, instance)))
 		} else {
 			spinner.Stop(true)
 		}
 
-		// Remove image
+		// Remove image, unless other instances of the game still use it
 		spinner = ui.NewSpinner(fmt.Sprintf("Removing %s image", game.Image))
 		spinner.Start()
-		if err := docker.RemoveImage(game.Image); err != nil {
+		if others, err := docker.GetStatus(gameName); err == nil && len(others) > 0 {
+			spinner.StopWithMessage(true, fmt.Sprintf("Image kept (used by %d other instance(s))", len(others)))
+		} else if err := docker.RemoveImage(game.Image); err != nil {
 			spinner.StopWithMessage(true, "Image not found (may be in use by other containers)")
 		} else {
 			spinner.Stop(true)
@@ -385,15 +869,19 @@ var uninstallCmd = &cobra.Command{
 		// Remove data directory
 		spinner = ui.NewSpinner("Removing data directory")
 		spinner.Start()
-		dataDir := fmt.Sprintf("./%s-server", gameName)
+		dataDir := server.Dir(instance)
 		if err := os.RemoveAll(dataDir); err != nil {
 			spinner.Stop(false)
 			return fmt.Errorf("failed to remove data directory: %w", err)
 		}
 		spinner.Stop(true)
 
-		fmt.Println()
-		ui.Success("%s uninstalled completely.", game.DisplayName)
+		ui.Println()
+		ui.Success("%s uninstalled completely.", serverTitle(game, instance))
+		if backups, err := backup.List(instance); err == nil && len(backups) > 0 {
+			dir, _ := backup.Dir(instance)
+			ui.Info("%d backup(s) kept in %s", len(backups), dir)
+		}
 
 		return nil
 	},
@@ -406,10 +894,15 @@ var logsCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
 		follow, _ := cmd.Flags().GetBool("follow")
 		tail, _ := cmd.Flags().GetString("tail")
 
-		containerName := "hostathome-" + gameName
+		containerName := "hostathome-" + instance
 
 		cmdArgs := []string{"logs"}
 		if follow {
@@ -428,10 +921,61 @@ var logsCmd = &cobra.Command{
 	},
 }
 
+var consoleCmd = &cobra.Command{
+	Use:   "console <game>",
+	Short: "Attach to the server console",
+	Long:  "Attach to the game server's console to type server commands. Detaching leaves the server running.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+		tail, _ := cmd.Flags().GetInt("tail")
+		detachKeys, _ := cmd.Flags().GetString("detach-keys")
+
+		console, err := docker.InspectConsole(instance)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+
+		if !console.Stdin {
+			ui.Warning("This container was created without console input; output only")
+			ui.Info("Recreate it to enable input: hostathome remove %s && hostathome run %s", serverRef(gameName, instance), serverRef(gameName, instance))
+		}
+		if console.TTY {
+			ui.Info("Attached to %s. Press Ctrl+C or %s to detach.", instance, detachKeys)
+		} else {
+			ui.Info("Attached to %s. Press Ctrl+C to detach.", instance)
+		}
+		ui.Println()
+
+		running, err := docker.AttachConsole(instance, docker.ConsoleOptions{
+			DetachKeys: detachKeys,
+			Tail:       tail,
+		})
+		ui.Println()
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+
+		if running {
+			ui.Info("Detached, server is still running")
+		} else {
+			ui.Warning("Server has stopped")
+		}
+		return nil
+	},
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status [game]",
 	Short: "Show server status",
-	Long:  "Show the status of running game servers.",
+	Long:  "Show the status of every game server instance, or of all instances of one game.",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var gameName string
@@ -444,65 +988,195 @@ var statusCmd = &cobra.Command{
 			return fmt.Errorf("failed to get status: %w", err)
 		}
 
+		if structured() {
+			outcomes := queryServers(statuses)
+			entries := []statusEntry{}
+			for _, s := range statuses {
+				entry := statusEntry{ContainerStatus: s}
+				if outcome, ok := outcomes[s.Instance]; ok {
+					entry.Query = newQueryReport(outcome)
+				}
+				entries = append(entries, entry)
+			}
+			return printData(entries)
+		}
+
 		if len(statuses) == 0 {
 			if gameName != "" {
 				ui.Info("No container found for %s", gameName)
 			} else {
 				ui.Info("No HostAtHome containers running")
 			}
-			fmt.Println()
+			ui.Println()
 			ui.Info("Install a game: hostathome install <game>")
 			ui.Info("List games: hostathome list")
 			return nil
 		}
 
 		ui.Title("Server Status")
-		fmt.Println()
+		ui.Println()
+
+		outcomes := queryServers(statuses)
+
+		limited := false
+		for _, s := range statuses {
+			if s.MemoryLimit > 0 || s.CPULimit > 0 || s.PidsLimit > 0 {
+				limited = true
+			}
+		}
 
-		headers := []string{"GAME", "STATUS", "PORTS", "CONTAINER"}
+		headers := []string{"GAME", "INSTANCE", "STATUS"}
+		if len(outcomes) > 0 {
+			headers = append(headers, "PLAYERS", "VERSION", "LATENCY")
+		}
+		if limited {
+			headers = append(headers, "LIMITS")
+		}
+		headers = append(headers, "PORTS", "CONTAINER")
 		var rows [][]string
 		for _, s := range statuses {
 			status := s.Status
+			outcome, queried := outcomes[s.Instance]
 			if s.Status == "running" {
-				status = ui.SymbolCheck + " running"
+				switch {
+				case s.Health == "unhealthy":
+					status = ui.SymbolWarning + " running (unhealthy)"
+				case s.Health == "starting" || (queried && outcome.Err != nil):
+					// The container runs before the game accepts players
+					status = ui.SymbolDot + " starting"
+				case s.Health == "healthy":
+					status = ui.SymbolCheck + " running (healthy)"
+				default:
+					status = ui.SymbolCheck + " running"
+				}
 			} else if s.Status == "exited" {
 				status = ui.SymbolCross + " stopped"
 			}
-			rows = append(rows, []string{s.Game, status, s.Ports, s.ContainerID[:12]})
+
+			row := []string{s.Game, s.Instance, status}
+			if len(outcomes) > 0 {
+				if r := outcome.Result; r != nil {
+					row = append(row, formatPlayers(r), r.Version, formatLatency(r.Latency))
+				} else {
+					row = append(row, "-", "-", "-")
+				}
+			}
+			if limited {
+				row = append(row, formatLimits(s))
+			}
+			rows = append(rows, append(row, s.Ports, s.ContainerID[:12]))
 		}
 		ui.Table(headers, rows)
 
+		// Messages of the day and player names are too long for the table
+		for _, s := range statuses {
+			r := outcomes[s.Instance].Result
+			if r == nil || (r.MOTD == "" && len(r.PlayerNames) == 0) {
+				continue
+			}
+			ui.Println()
+			ui.Title("%s", s.Instance)
+			if r.MOTD != "" {
+				ui.Detail("MOTD", r.MOTD)
+			}
+			if len(r.PlayerNames) > 0 {
+				ui.Detail("Online", strings.Join(r.PlayerNames, ", "))
+			}
+		}
+
 		return nil
 	},
 }
 
+// statusEntry is a server in structured status output
+type statusEntry struct {
+	docker.ContainerStatus `yaml:",inline"`
+	// Query is set if the game supports status queries and the server is running
+	Query *queryReport `json:"query,omitempty" yaml:"query,omitempty"`
+}
+
+// gameSummary is a game in structured list output
+type gameSummary struct {
+	Name        string   `json:"name" yaml:"name"`
+	DisplayName string   `json:"display_name" yaml:"display_name"`
+	Description string   `json:"description" yaml:"description"`
+	Image       string   `json:"image" yaml:"image"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Registry    string   `json:"registry" yaml:"registry"`
+	Shadows     []string `json:"shadows,omitempty" yaml:"shadows,omitempty"`
+}
+
+// newGameSummary converts a game definition for structured output
+func newGameSummary(g registry.Game) gameSummary {
+	return gameSummary{
+		Name:        g.Name,
+		DisplayName: g.DisplayName,
+		Description: g.Description,
+		Image:       g.Image,
+		Tags:        g.Tags,
+		Registry:    g.Registry,
+		Shadows:     g.Shadows,
+	}
+}
+
+// fetchGames lists the games in all registries, warning about games that
+// couldn't be loaded
+func fetchGames() ([]registry.Game, error) {
+	spinner := ui.NewSpinner("Fetching game list")
+	spinner.Start()
+
+	games, err := registry.ListGames()
+	var partial *registry.PartialError
+	if errors.As(err, &partial) {
+		spinner.Stop(true)
+		for _, e := range partial.Errors {
+			ui.Warning("Skipped %v", e)
+		}
+		return games, nil
+	}
+	if err != nil {
+		spinner.Stop(false)
+		ui.Error("Failed to fetch game list: %v", err)
+		return nil, err
+	}
+	spinner.Stop(true)
+	return games, nil
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available games",
 	Long:  "Show all games available in the registry.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		spinner := ui.NewSpinner("Fetching game list")
-		spinner.Start()
-
-		games, err := registry.ListGames()
+		games, err := fetchGames()
 		if err != nil {
-			spinner.Stop(false)
 			return err
 		}
-		spinner.Stop(true)
 
-		fmt.Println()
+		if structured() {
+			summaries := []gameSummary{}
+			for _, g := range games {
+				summaries = append(summaries, newGameSummary(g))
+			}
+			return printData(summaries)
+		}
+
+		ui.Println()
 		ui.Title("Available Games")
-		fmt.Println()
+		ui.Println()
 
-		headers := []string{"GAME", "DESCRIPTION"}
+		headers := []string{"GAME", "REGISTRY", "DESCRIPTION"}
 		var rows [][]string
 		for _, g := range games {
-			rows = append(rows, []string{g.Name, g.Description})
+			source := g.Registry
+			if len(g.Shadows) > 0 {
+				source += " (overrides " + strings.Join(g.Shadows, ", ") + ")"
+			}
+			rows = append(rows, []string{g.Name, source, g.Description})
 		}
 		ui.Table(headers, rows)
 
-		fmt.Println()
+		ui.Println()
 		ui.Info("Install: hostathome install <game>")
 
 		return nil
@@ -510,10 +1184,71 @@ var listCmd = &cobra.Command{
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table, json or yaml")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Use only cached game definitions and local images, without network access")
+	rootCmd.PersistentFlags().StringArrayVar(&registryFlags, "registry", nil, "Game registry as [name=]location (URL, file:// URL or directory); repeat to layer, highest priority first")
+
 	logsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
 	logsCmd.Flags().StringP("tail", "n", "100", "Number of lines to show")
 
+	consoleCmd.Flags().IntP("tail", "n", 20, "Number of recent log lines to show before attaching")
+	consoleCmd.Flags().String("detach-keys", docker.DefaultDetachKeys, "Key sequence to detach without stopping the server")
+
+	for _, c := range []*cobra.Command{stopCmd, restartCmd, removeCmd} {
+		c.Flags().Int("timeout", 0, "Seconds the server gets to shut down before it is killed (default: from the game definition)")
+		c.Flags().Bool("no-warn", false, "Skip the in-game shutdown countdown")
+	}
+
+	for _, c := range []*cobra.Command{runCmd, restartCmd} {
+		c.Flags().Bool("wait", false, "Wait until the server is ready for players")
+		c.Flags().Duration("wait-timeout", 0, "How long to wait with --wait (default: from the game definition, or 5m)")
+	}
+
+	searchCmd.Flags().StringSliceP("tag", "t", nil, "Only show games with this tag (repeat or comma-separate to require several)")
+
+	statsCmd.Flags().Bool("no-stream", false, "Print a single sample and exit")
+
+	rconCmd.Flags().String("password", "", "RCON password (default: read from the server config)")
+
 	runCmd.Flags().BoolVarP(&devMode, "dev", "d", false, "Use local dev image instead of registry")
+	runCmd.Flags().BoolVar(&autoPorts, "auto-ports", false, "Pick the next free host port when a port is already in use")
+	runCmd.Flags().StringArrayVarP(&portFlags, "port", "p", nil, "Override a host port as name=port (e.g. player=25565); saved for future runs")
+	runCmd.Flags().String("memory", "", "Memory limit (e.g. 4g, 0 for none); saved for future runs")
+	runCmd.Flags().String("swap", "", "Swap allowed on top of the memory limit (e.g. 1g, -1 for unlimited)")
+	runCmd.Flags().Float64("cpus", 0, "Number of CPUs the server may use (e.g. 1.5, 0 for no limit)")
+	runCmd.Flags().Int64("cpu-shares", 0, "CPU weight relative to other containers (0 for Docker's default of 1024)")
+	runCmd.Flags().Int64("pids-limit", 0, "Maximum number of processes (0 for no limit)")
+
+	backupCmd.Flags().Bool("pause", false, "Pause the container while backing up")
+	backupCmd.Flags().Bool("stop", false, "Stop the container while backing up and start it again afterwards")
+	backupCmd.MarkFlagsMutuallyExclusive("pause", "stop")
+
+	restoreCmd.Flags().Bool("force", false, "Restore even if the backup was made for a different game")
+
+	scheduleCmd.Flags().String("cron", "", "Backup schedule as a cron expression or @hourly/@daily/@weekly")
+	scheduleCmd.Flags().String("mode", "", "Pause or stop the container during backups (pause|stop)")
+	scheduleCmd.Flags().Int("keep-last", 0, "Keep the newest N backups")
+	scheduleCmd.Flags().Int("keep-daily", 0, "Keep one backup per day for N days")
+	scheduleCmd.Flags().Int("keep-weekly", 0, "Keep one backup per week for N weeks")
+	scheduleCmd.Flags().Bool("disable", false, "Remove the backup schedule")
+
+	pruneCmd.Flags().Bool("dry-run", false, "Show which backups would be deleted")
+
+	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cacheRefreshCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	envCmd.AddCommand(envSetCmd)
+	envCmd.AddCommand(envUnsetCmd)
+	envCmd.AddCommand(envListCmd)
+	envListCmd.Flags().Bool("show-values", false, "Show secret and undeclared values")
+
+	agentCmd.Flags().Bool("once", false, "Run due backups once and exit")
+	agentCmd.AddCommand(agentInstallCmd)
+
+	for _, c := range []*cobra.Command{installCmd, runCmd, stopCmd, restartCmd, removeCmd, uninstallCmd, logsCmd, consoleCmd, rconCmd, backupCmd, backupsCmd, restoreCmd, scheduleCmd, pruneCmd, envSetCmd, envUnsetCmd, envListCmd} {
+		c.Flags().StringVar(&instanceName, "name", "", "Server instance name (default: game name)")
+	}
 
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(installCmd)
@@ -523,6 +1258,19 @@ func init() {
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(consoleCmd)
+	rootCmd.AddCommand(rconCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(backupsCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(cacheCmd)
 }
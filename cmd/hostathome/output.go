@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hostathome/cli/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormat string
+
+// setupOutput validates --output and, for structured formats, sends messages
+// to stderr so scripts can parse the data on stdout
+func setupOutput() error {
+	switch outputFormat {
+	case outputTable:
+		return nil
+	case outputJSON, outputYAML:
+		ui.SetOutput(os.Stderr)
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q (expected table, json or yaml)", outputFormat)
+	}
+}
+
+// structured reports whether the command should print data instead of tables
+func structured() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// printData writes v in the selected structured format
+func printData(v any) error {
+	if outputFormat == outputYAML {
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
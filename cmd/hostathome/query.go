@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/query"
+	"github.com/hostathome/cli/internal/registry"
+)
+
+const queryTimeout = 2 * time.Second
+
+// queryOutcome is the result of querying one server
+type queryOutcome struct {
+	Result *query.Result
+	Err    error
+}
+
+// queryServers asks every running server whose game declares a query protocol
+// for its status, in parallel. The outcomes are keyed by instance; servers that
+// can't be queried are left out.
+func queryServers(statuses []docker.ContainerStatus) map[string]queryOutcome {
+	outcomes := make(map[string]queryOutcome)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, s := range statuses {
+		if s.Status != "running" {
+			continue
+		}
+		game, err := registry.GetGame(s.Game)
+		if err != nil || game.Query == nil {
+			continue
+		}
+		port := s.HostPorts[game.Query.PortName()]
+		if port == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(instance string, q *registry.Query, port int) {
+			defer wg.Done()
+			result, err := query.Query(q.Protocol, net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), queryTimeout)
+			mu.Lock()
+			outcomes[instance] = queryOutcome{Result: result, Err: err}
+			mu.Unlock()
+		}(s.Instance, game.Query, port)
+	}
+
+	wg.Wait()
+	return outcomes
+}
+
+// formatPlayers renders a player count such as "3/20"
+func formatPlayers(r *query.Result) string {
+	return fmt.Sprintf("%d/%d", r.Players, r.MaxPlayers)
+}
+
+// formatLatency renders a round trip time in milliseconds, or "-" if unknown
+func formatLatency(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	if d < time.Millisecond {
+		return "<1ms"
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// queryReport is a query outcome in structured output
+type queryReport struct {
+	Version     string   `json:"version,omitempty" yaml:"version,omitempty"`
+	MOTD        string   `json:"motd,omitempty" yaml:"motd,omitempty"`
+	Players     int      `json:"players" yaml:"players"`
+	MaxPlayers  int      `json:"max_players" yaml:"max_players"`
+	PlayerNames []string `json:"player_names,omitempty" yaml:"player_names,omitempty"`
+	LatencyMS   float64  `json:"latency_ms" yaml:"latency_ms"`
+	// Error is set if the server didn't answer, usually because it is still starting
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// newQueryReport converts a query outcome for structured output
+func newQueryReport(o queryOutcome) *queryReport {
+	if o.Err != nil {
+		return &queryReport{Error: o.Err.Error()}
+	}
+	r := o.Result
+	return &queryReport{
+		Version:     r.Version,
+		MOTD:        r.MOTD,
+		Players:     r.Players,
+		MaxPlayers:  r.MaxPlayers,
+		PlayerNames: r.PlayerNames,
+		LatencyMS:   float64(r.Latency.Microseconds()) / 1000,
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hostathome/cli/internal/config"
+	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/query"
+	"github.com/hostathome/cli/internal/rcon"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	rconTimeout     = 10 * time.Second
+	rconHistoryFile = "rcon_history"
+	rconHistorySize = 500
+)
+
+var rconCmd = &cobra.Command{
+	Use:   "rcon <game> [command]",
+	Short: "Send RCON commands to a server",
+	Long: `Run a command on the game server over RCON and print the response.
+
+Without a command, an interactive prompt is opened. Use the arrow keys to recall
+earlier commands and Ctrl+D or "exit" to quit. The password is read from the
+server's configs/config.yaml unless --password is given.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gameName := args[0]
+		instance, err := instanceFor(gameName)
+		if err != nil {
+			ui.Error("%v", err)
+			return err
+		}
+		password, _ := cmd.Flags().GetString("password")
+
+		client, err := dialRCON(gameName, instance, password)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		if len(args) > 1 {
+			out, err := client.Execute(strings.Join(args[1:], " "))
+			if err != nil {
+				ui.Error("RCON command failed: %v", err)
+				return err
+			}
+			printRCONOutput(out)
+			return nil
+		}
+
+		return rconREPL(client, instance)
+	},
+}
+
+// dialRCON connects to the RCON port a server instance publishes
+func dialRCON(gameName, instance, password string) (*rcon.Client, error) {
+	game, err := registry.GetGame(gameName)
+	if err != nil {
+		reportGameError(gameName, err)
+		return nil, err
+	}
+
+	client, err := connectRCON(game, instance, password)
+	if err != nil {
+		ui.Error("%v", err)
+		ui.Info("Check that the server is running (hostathome status) and the RCON password in %s", server.ConfigPath(instance))
+		return nil, err
+	}
+	return client, nil
+}
+
+// connectRCON connects to a server instance's RCON port, reading the password
+// from the server config if none is given
+func connectRCON(game *registry.Game, instance, password string) (*rcon.Client, error) {
+	if game.Port("rcon") == nil {
+		return nil, fmt.Errorf("%s does not define an RCON port", game.DisplayName)
+	}
+
+	port, err := docker.PublishedPort(instance, game, "rcon")
+	if err != nil {
+		return nil, err
+	}
+	if port == 0 {
+		return nil, fmt.Errorf("the RCON port of %s is not published", serverTitle(game, instance))
+	}
+
+	if password == "" {
+		if password, err = server.RCONPassword(instance); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := rcon.Dial(net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), password, rconTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RCON on port %d: %w", port, err)
+	}
+	return client, nil
+}
+
+// rconREPL reads commands interactively until EOF or "exit"
+func rconREPL(client *rcon.Client, instance string) error {
+	history := loadRCONHistory()
+	defer func() { saveRCONHistory(history) }()
+
+	ui.Info("Connected to %s. Type \"exit\" or press Ctrl+D to quit.", instance)
+	reader := ui.NewLineReader(instance+"> ", history)
+
+	for {
+		line, err := reader.ReadLine()
+		history = reader.History()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if line == "exit" || line == "quit" {
+			return nil
+		}
+
+		out, err := client.Execute(line)
+		if err != nil {
+			ui.Error("RCON command failed: %v", err)
+			return err
+		}
+		printRCONOutput(out)
+	}
+}
+
+// printRCONOutput prints a command response without formatting codes
+func printRCONOutput(out string) {
+	out = strings.TrimRight(query.StripFormatting(out), "\n")
+	if out != "" {
+		fmt.Println(out)
+	}
+}
+
+// rconHistoryPath returns the file interactive RCON commands are saved in
+func rconHistoryPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, rconHistoryFile), nil
+}
+
+// loadRCONHistory reads saved RCON commands, oldest first
+func loadRCONHistory() []string {
+	path, err := rconHistoryPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
+
+// saveRCONHistory writes the most recent RCON commands. Commands can contain
+// secrets, so the file is only readable by the user.
+func saveRCONHistory(history []string) {
+	if len(history) == 0 {
+		return
+	}
+	if len(history) > rconHistorySize {
+		history = history[len(history)-rconHistorySize:]
+	}
+	path, err := rconHistoryPath()
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600)
+}
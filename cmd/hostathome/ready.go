@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/query"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/ui"
+)
+
+// defaultReadyTimeout is how long a server may take to become ready if its game doesn't say
+const defaultReadyTimeout = 5 * time.Minute
+
+// waitForServer waits until a started server is ready for players. If it dies
+// or doesn't become ready in time, its last log lines are printed.
+func waitForServer(game *registry.Game, instance string, timeout time.Duration) error {
+	opts := docker.ReadyOptions{Timeout: readyTimeout(game, timeout)}
+
+	if game.Ready != nil && game.Ready.Log != "" {
+		pattern, err := regexp.Compile(game.Ready.Log)
+		if err != nil {
+			ui.Error("Invalid ready log pattern in the %s definition: %v", game.DisplayName, err)
+			return err
+		}
+		opts.LogPattern = pattern
+	}
+
+	if game.Query != nil {
+		if port, err := docker.PublishedPort(instance, game, game.Query.PortName()); err == nil && port > 0 {
+			addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+			opts.Probe = func() error {
+				_, err := query.Query(game.Query.Protocol, addr, queryTimeout)
+				return err
+			}
+		}
+	}
+
+	spinner := ui.NewSpinner(fmt.Sprintf("Waiting for %s to be ready", serverTitle(game, instance)))
+	spinner.Start()
+	start := time.Now()
+
+	method, err := docker.WaitReady(instance, opts)
+	if err == nil {
+		spinner.StopWithMessage(true, fmt.Sprintf("Ready after %s (%s)", time.Since(start).Round(time.Second), method))
+		return nil
+	}
+	spinner.Stop(false)
+
+	var startupErr *docker.StartupError
+	switch {
+	case errors.As(err, &startupErr):
+		ui.Error("%s %s during startup", serverTitle(game, instance), startupErr.Reason)
+		printLogLines(startupErr.Logs)
+	case errors.Is(err, docker.ErrNotReady):
+		ui.Warning("%s is not ready after %s (waiting for %s)", serverTitle(game, instance), opts.Timeout, method)
+		logs, _ := docker.RecentLogs(instance, 20)
+		printLogLines(logs)
+		ui.Println()
+		ui.Info("It may still be starting: hostathome logs -f %s", serverRef(game.Name, instance))
+	default:
+		ui.Error("Failed to check readiness: %v", err)
+	}
+	return err
+}
+
+// readyTimeout returns how long to wait for a server to become ready
+func readyTimeout(game *registry.Game, timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	if game.Ready != nil && game.Ready.Timeout > 0 {
+		return time.Duration(game.Ready.Timeout) * time.Second
+	}
+	return defaultReadyTimeout
+}
+
+// printLogLines prints server log lines below an error
+func printLogLines(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	ui.Println()
+	ui.Info("Last log lines:")
+	for _, line := range lines {
+		ui.Println("   " + line)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search available games",
+	Long: `Search the registry for games by name, display name and description.
+Typos and abbreviations are tolerated; exact name matches are listed first.
+Use --tag to only show games in a category, e.g. --tag survival.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var query string
+		if len(args) > 0 {
+			query = args[0]
+		}
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if strings.TrimSpace(query) == "" && len(tags) == 0 {
+			ui.Error("Give a search query or --tag")
+			return fmt.Errorf("no search query")
+		}
+
+		games, err := fetchGames()
+		if err != nil {
+			return err
+		}
+
+		results := registry.Search(games, query, tags)
+
+		if structured() {
+			summaries := []gameSummary{}
+			for _, g := range results {
+				summaries = append(summaries, newGameSummary(g))
+			}
+			return printData(summaries)
+		}
+
+		ui.Println()
+		if len(results) == 0 {
+			ui.Info("No games match")
+			ui.Info("List all games: hostathome list")
+			return nil
+		}
+
+		headers := []string{"GAME", "TAGS", "DESCRIPTION"}
+		var rows [][]string
+		for _, g := range results {
+			rows = append(rows, []string{g.Name, strings.Join(g.Tags, ", "), g.Description})
+		}
+		ui.Table(headers, rows)
+
+		ui.Println()
+		ui.Info("Details: hostathome info <game>")
+
+		return nil
+	},
+}
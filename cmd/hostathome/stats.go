@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	statsInterval = 2 * time.Second
+	// diskUsageInterval limits how often server directories are walked, since worlds can be large
+	diskUsageInterval = 30 * time.Second
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [game]",
+	Short: "Show server resource usage",
+	Long: `Show CPU, memory, network and disk usage of running game servers,
+refreshing every few seconds until interrupted. Use --no-stream for a single sample.
+With --output json or yaml a single sample is printed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var gameName string
+		if len(args) > 0 {
+			gameName = args[0]
+		}
+		noStream, _ := cmd.Flags().GetBool("no-stream")
+
+		disk := &diskUsageCache{sizes: make(map[string]int64), checked: make(map[string]time.Time)}
+
+		if structured() {
+			// A stream of documents is awkward to parse, so print one sample
+			stats, err := docker.GetStats(gameName)
+			if err != nil {
+				ui.Error("Failed to get stats: %v", err)
+				return err
+			}
+			entries := []statsEntry{}
+			for _, s := range stats {
+				entry := statsEntry{ContainerStats: s}
+				if size, err := server.DiskUsage(s.Instance); err == nil {
+					entry.DiskUsage = &size
+				}
+				entries = append(entries, entry)
+			}
+			return printData(entries)
+		}
+
+		if noStream {
+			stats, err := docker.GetStats(gameName)
+			if err != nil {
+				ui.Error("Failed to get stats: %v", err)
+				return err
+			}
+			printStats(stats, disk)
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		stream, err := docker.StreamStats(gameName)
+		if err != nil {
+			ui.Error("Failed to get stats: %v", err)
+			return err
+		}
+		defer stream.Close()
+
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+		for {
+			stats, err := stream.Stats()
+			if err != nil {
+				ui.Error("Failed to get stats: %v", err)
+				return err
+			}
+			ui.ClearScreen()
+			printStats(stats, disk)
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// statsEntry is a server in structured stats output
+type statsEntry struct {
+	docker.ContainerStats `yaml:",inline"`
+	// DiskUsage is the size of the server directory, if it could be read
+	DiskUsage *int64 `json:"disk_usage,omitempty" yaml:"disk_usage,omitempty"`
+}
+
+// printStats renders a stats sample as a table
+func printStats(stats []docker.ContainerStats, disk *diskUsageCache) {
+	if len(stats) == 0 {
+		ui.Info("No HostAtHome servers running")
+		return
+	}
+
+	headers := []string{"INSTANCE", "GAME", "CPU %", "MEMORY", "NET I/O", "BLOCK I/O", "PIDS", "DISK"}
+	var rows [][]string
+	for _, s := range stats {
+		memory := ui.FormatBytes(int64(s.MemoryUsage))
+		if s.MemoryLimit > 0 {
+			memory += " / " + ui.FormatBytes(int64(s.MemoryLimit))
+		}
+		rows = append(rows, []string{
+			s.Instance,
+			s.Game,
+			fmt.Sprintf("%.1f%%", s.CPUPercent),
+			memory,
+			ui.FormatBytes(int64(s.NetRx)) + " / " + ui.FormatBytes(int64(s.NetTx)),
+			ui.FormatBytes(int64(s.BlockRead)) + " / " + ui.FormatBytes(int64(s.BlockWrite)),
+			fmt.Sprintf("%d", s.PIDs),
+			disk.get(s.Instance),
+		})
+	}
+	ui.Table(headers, rows)
+}
+
+// diskUsageCache remembers the size of server directories between refreshes
+type diskUsageCache struct {
+	sizes   map[string]int64
+	checked map[string]time.Time
+}
+
+// get returns the formatted disk usage of a server directory, or "-" if it can't be read
+func (d *diskUsageCache) get(instance string) string {
+	if time.Since(d.checked[instance]) >= diskUsageInterval {
+		size, err := server.DiskUsage(instance)
+		if err != nil {
+			size = -1
+		}
+		d.sizes[instance] = size
+		d.checked[instance] = time.Now()
+	}
+	if d.sizes[instance] < 0 {
+		return "-"
+	}
+	return ui.FormatBytes(d.sizes[instance])
+}
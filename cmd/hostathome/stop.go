@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hostathome/cli/internal/docker"
+	"github.com/hostathome/cli/internal/rcon"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/ui"
+)
+
+// shutdownServer runs the game's graceful shutdown sequence over RCON, if it
+// has one and the server is running, and returns how its container should then
+// be stopped. The warning countdown is skipped unless warn is set. A positive
+// timeout (in seconds) overrides the game's stop timeout.
+func shutdownServer(game *registry.Game, instance string, timeout int, warn bool) docker.StopOptions {
+	opts := docker.StopOptions{Timeout: stopTimeout(game, timeout)}
+
+	sd := game.Shutdown
+	if sd == nil || (len(sd.Commands) == 0 && (!warn || sd.Warning == "" || len(sd.Countdown) == 0)) {
+		return opts
+	}
+	// Find the container first, the server may exit before the commands return
+	id, err := docker.RunningContainerID(instance)
+	if err != nil || id == "" {
+		return opts
+	}
+	opts.ContainerID = id
+
+	client, err := connectRCON(game, instance, "")
+	if err != nil {
+		ui.Warning("Skipping graceful shutdown: %v", err)
+		return opts
+	}
+	defer client.Close()
+
+	if warn && sd.Warning != "" {
+		if err := shutdownCountdown(client, sd); err != nil {
+			ui.Warning("Failed to warn players: %v", err)
+		}
+	}
+
+	if len(sd.Commands) > 0 {
+		opts.Since = time.Now()
+	}
+	for i, command := range sd.Commands {
+		ui.Step("Sending '%s'", command)
+		if _, err := client.Execute(command); err != nil {
+			// The last command usually stops the server, which may drop the connection before answering
+			if i < len(sd.Commands)-1 {
+				ui.Warning("RCON command '%s' failed: %v", command, err)
+				return opts
+			}
+		}
+	}
+	return opts
+}
+
+// shutdownCountdown broadcasts the shutdown warning at each countdown mark
+func shutdownCountdown(client *rcon.Client, sd *registry.Shutdown) error {
+	var marks []int
+	for _, secs := range sd.Countdown {
+		if secs > 0 {
+			marks = append(marks, secs)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(marks)))
+
+	for i, secs := range marks {
+		ui.Step("Warning players: %d seconds left", secs)
+		if _, err := client.Execute(strings.ReplaceAll(sd.Warning, "{seconds}", strconv.Itoa(secs))); err != nil {
+			return err
+		}
+		next := 0
+		if i+1 < len(marks) {
+			next = marks[i+1]
+		}
+		time.Sleep(time.Duration(secs-next) * time.Second)
+	}
+	return nil
+}
+
+// stopTimeout returns how long a server gets to exit before it is killed,
+// or 0 for Docker's default
+func stopTimeout(game *registry.Game, seconds int) time.Duration {
+	if seconds <= 0 && game.Shutdown != nil {
+		seconds = game.Shutdown.Timeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// reportStop tells the user whether a server exited cleanly or was killed
+func reportStop(result *docker.StopResult) {
+	if result == nil {
+		return
+	}
+	if result.Killed {
+		ui.Warning("Server did not exit in time and was killed")
+		ui.Info("Increase the timeout with --timeout if it needs longer to save")
+		return
+	}
+	ui.Info("Server exited cleanly (exit code %d)", result.ExitCode)
+}
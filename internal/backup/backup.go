@@ -0,0 +1,218 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hostathome/cli/internal/config"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+)
+
+const (
+	archiveExt      = ".tar.gz"
+	metadataExt     = ".json"
+	timestampFormat = "20060102-150405"
+)
+
+// backedUpDirs are the server directories included in a backup
+var backedUpDirs = []string{"data", "configs"}
+
+// Info describes a backup archive. It is stored next to the archive as JSON.
+type Info struct {
+	Name     string    `json:"name" yaml:"name"`
+	Game     string    `json:"game" yaml:"game"`
+	Instance string    `json:"instance" yaml:"instance"`
+	Image    string    `json:"image" yaml:"image"`
+	Created  time.Time `json:"created" yaml:"created"`
+	Size     int64     `json:"size" yaml:"size"`
+	SHA256   string    `json:"sha256" yaml:"sha256"`
+
+	// dir is the backup directory the archive is in
+	dir string
+}
+
+// Dir returns the backup directory for a server instance
+func Dir(instance string) (string, error) {
+	dir, err := config.BackupsDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find backup directory: %w", err)
+	}
+	return filepath.Join(dir, instance), nil
+}
+
+// Path returns the archive path of a backup
+func (i *Info) Path() string {
+	return filepath.Join(i.dir, i.Name)
+}
+
+// Create archives the data and configs directories of a server instance into
+// a timestamped, gzip-compressed tarball and records its metadata
+func Create(instance string, game *registry.Game) (*Info, error) {
+	serverDir := server.Dir(instance)
+	if _, err := os.Stat(serverDir); err != nil {
+		return nil, fmt.Errorf("server directory %s not found", serverDir)
+	}
+
+	backupDir, err := Dir(instance)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	created := time.Now().UTC()
+	info := &Info{
+		Name:     fmt.Sprintf("%s-%s%s", instance, created.Format(timestampFormat), archiveExt),
+		Game:     game.Name,
+		Instance: instance,
+		Image:    game.Image,
+		Created:  created,
+		dir:      backupDir,
+	}
+
+	archivePath := info.Path()
+	if _, err := os.Stat(archivePath); err == nil {
+		return nil, fmt.Errorf("backup %s already exists", info.Name)
+	}
+
+	// Write to a temp file first so an interrupted backup never looks complete
+	tmpPath := archivePath + ".tmp"
+	size, sum, err := writeArchive(tmpPath, serverDir)
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	info.Size = size
+	info.SHA256 = sum
+
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+
+	if err := writeMetadata(info); err != nil {
+		return nil, fmt.Errorf("failed to write backup metadata: %w", err)
+	}
+
+	return info, nil
+}
+
+// writeArchive writes a tar.gz of the backed up directories and returns its size and SHA-256
+func writeArchive(path, serverDir string) (int64, string, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	gz := gzip.NewWriter(io.MultiWriter(file, hash, counter))
+	tw := tar.NewWriter(gz)
+
+	for _, dir := range backedUpDirs {
+		if err := addDir(tw, serverDir, dir); err != nil {
+			return 0, "", err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, "", err
+	}
+	if err := file.Sync(); err != nil {
+		return 0, "", err
+	}
+
+	return counter.n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// addDir adds a directory under serverDir to the archive, with paths relative to serverDir
+func addDir(tw *tar.Writer, serverDir, dir string) error {
+	root := filepath.Join(serverDir, dir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(serverDir, path)
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if fi.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(tw, f); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+		return nil
+	})
+}
+
+// writeMetadata saves backup metadata next to its archive
+func writeMetadata(info *Info) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metadataPath(info.Path()), data, 0644)
+}
+
+// metadataPath returns the metadata file for an archive
+func metadataPath(archivePath string) string {
+	return strings.TrimSuffix(archivePath, archiveExt) + metadataExt
+}
+
+// countingWriter counts bytes written through it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
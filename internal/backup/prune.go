@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hostathome/cli/internal/server"
+)
+
+// Prune deletes the backups of a server instance not kept by the retention
+// rules and returns them. With dryRun set nothing is deleted. An empty
+// retention policy keeps everything.
+func Prune(instance string, retention server.Retention, dryRun bool) ([]*Info, error) {
+	if retention.IsZero() {
+		return nil, nil
+	}
+
+	backups, err := List(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []*Info
+	keep := retained(backups, retention, time.Now())
+	for _, b := range backups {
+		if keep[b.Name] {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(b.Path()); err != nil && !os.IsNotExist(err) {
+				return pruned, fmt.Errorf("failed to delete %s: %w", b.Name, err)
+			}
+			if err := os.Remove(metadataPath(b.Path())); err != nil && !os.IsNotExist(err) {
+				return pruned, fmt.Errorf("failed to delete %s metadata: %w", b.Name, err)
+			}
+		}
+		pruned = append(pruned, b)
+	}
+	return pruned, nil
+}
+
+// keptSafetyCopies is how many of the newest safety copies PruneSafetyCopies keeps
+const keptSafetyCopies = 1
+
+// PruneSafetyCopies deletes all but the newest safety copy of a server instance
+// and returns them. With dryRun set nothing is deleted.
+func PruneSafetyCopies(instance string, dryRun bool) ([]*SafetyCopy, error) {
+	copies, err := SafetyCopies(instance)
+	if err != nil || len(copies) <= keptSafetyCopies {
+		return nil, err
+	}
+
+	var pruned []*SafetyCopy
+	for _, c := range copies[keptSafetyCopies:] {
+		if !dryRun {
+			if err := os.RemoveAll(c.Path); err != nil {
+				return pruned, fmt.Errorf("failed to delete %s: %w", c.Name, err)
+			}
+		}
+		pruned = append(pruned, c)
+	}
+	return pruned, nil
+}
+
+// retained returns the names of backups kept by the retention rules.
+// backups must be sorted newest first.
+func retained(backups []*Info, r server.Retention, now time.Time) map[string]bool {
+	keep := make(map[string]bool)
+
+	for i, b := range backups {
+		if i < r.KeepLast {
+			keep[b.Name] = true
+		}
+	}
+
+	// Keep the newest backup of each period since oldest
+	keepPeriods := func(oldest time.Time, period func(time.Time) string) {
+		seen := make(map[string]bool)
+		for _, b := range backups {
+			created := b.Created.Local()
+			if created.Before(oldest) {
+				break
+			}
+			p := period(created)
+			if !seen[p] {
+				seen[p] = true
+				keep[b.Name] = true
+			}
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if r.KeepDaily > 0 {
+		keepPeriods(today.AddDate(0, 0, -(r.KeepDaily-1)), func(t time.Time) string {
+			return t.Format("2006-01-02")
+		})
+	}
+	if r.KeepWeekly > 0 {
+		// ISO weeks start on Monday
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		keepPeriods(monday.AddDate(0, 0, -7*(r.KeepWeekly-1)), func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%02d", year, week)
+		})
+	}
+
+	return keep
+}
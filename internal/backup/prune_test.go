@@ -0,0 +1,197 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hostathome/cli/internal/server"
+)
+
+// now is a Wednesday; the ISO week started on Monday 2025-03-10
+var now = time.Date(2025, 3, 12, 12, 0, 0, 0, time.Local)
+
+// backupAt returns a backup made at the given day and hour of March 2025
+func backupAt(day, hour int) *Info {
+	created := time.Date(2025, 3, day, hour, 0, 0, 0, time.Local)
+	return &Info{
+		Name:     "mc-" + created.Format(timestampFormat) + archiveExt,
+		Instance: "mc",
+		Created:  created.UTC(),
+	}
+}
+
+func names(backups []*Info) []string {
+	var result []string
+	for _, b := range backups {
+		result = append(result, b.Name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestRetained(t *testing.T) {
+	backups := []*Info{
+		backupAt(12, 10),
+		backupAt(12, 8),
+		backupAt(11, 20),
+		backupAt(11, 6),
+		backupAt(10, 12),
+		backupAt(9, 12),
+		backupAt(4, 12),
+		backupAt(2, 12),
+	}
+
+	tests := []struct {
+		name      string
+		retention server.Retention
+		want      []*Info
+	}{
+		{"none", server.Retention{}, nil},
+		{"last", server.Retention{KeepLast: 2}, backups[:2]},
+		{"last more than available", server.Retention{KeepLast: 20}, backups},
+		{"daily", server.Retention{KeepDaily: 3}, []*Info{backups[0], backups[2], backups[4]}},
+		{"daily today only", server.Retention{KeepDaily: 1}, []*Info{backups[0]}},
+		{"weekly", server.Retention{KeepWeekly: 2}, []*Info{backups[0], backups[5]}},
+		{"weekly beyond history", server.Retention{KeepWeekly: 3}, []*Info{backups[0], backups[5], backups[7]}},
+		{"combined", server.Retention{KeepLast: 1, KeepDaily: 2, KeepWeekly: 2}, []*Info{backups[0], backups[2], backups[5]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep := retained(backups, tt.retention, now)
+			var got []string
+			for name := range keep {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if want := names(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("kept %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	dir, err := Dir("mc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var backups []*Info
+	for _, day := range []int{12, 11, 10, 9} {
+		b := backupAt(day, 12)
+		b.dir = dir
+		if err := os.WriteFile(b.Path(), []byte("archive"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeMetadata(b); err != nil {
+			t.Fatal(err)
+		}
+		backups = append(backups, b)
+	}
+	retention := server.Retention{KeepLast: 2}
+
+	pruned, err := Prune("mc", retention, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if got, want := names(pruned), names(backups[2:]); !reflect.DeepEqual(got, want) {
+		t.Errorf("dry run pruned %v, want %v", got, want)
+	}
+	if left, _ := List("mc"); len(left) != len(backups) {
+		t.Fatalf("dry run deleted backups, %d left", len(left))
+	}
+
+	if _, err := Prune("mc", retention, false); err != nil {
+		t.Fatal(err)
+	}
+	left, err := List("mc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(left), names(backups[:2]); !reflect.DeepEqual(got, want) {
+		t.Errorf("kept %v, want %v", got, want)
+	}
+	for _, b := range backups[2:] {
+		for _, path := range []string{b.Path(), metadataPath(b.Path())} {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("%s not deleted", filepath.Base(path))
+			}
+		}
+	}
+}
+
+func TestPruneSafetyCopies(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	dir, err := Dir("mc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copies []string
+	for _, day := range []int{12, 10, 11} {
+		name := safetyPrefix + time.Date(2025, 3, day, 12, 0, 0, 0, time.UTC).Format(timestampFormat)
+		if err := os.MkdirAll(filepath.Join(dir, name, "data"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "data", "world.dat"), []byte("world"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		copies = append(copies, name)
+	}
+	// Not a safety copy
+	if err := os.MkdirAll(filepath.Join(dir, safetyPrefix+"manual"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := SafetyCopies("mc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range listed {
+		got = append(got, c.Name)
+		if c.Size != int64(len("world")) {
+			t.Errorf("%s size = %d", c.Name, c.Size)
+		}
+	}
+	if want := []string{copies[0], copies[2], copies[1]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SafetyCopies() = %v, want %v", got, want)
+	}
+
+	pruned, err := PruneSafetyCopies("mc", true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(pruned) != 2 {
+		t.Fatalf("dry run pruned %d copies, want 2", len(pruned))
+	}
+	if left, _ := SafetyCopies("mc"); len(left) != 3 {
+		t.Fatalf("dry run deleted copies, %d left", len(left))
+	}
+
+	if _, err := PruneSafetyCopies("mc", false); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range copies {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if kept := i == 0; kept != (err == nil) {
+			t.Errorf("%s kept = %v, want %v", name, err == nil, kept)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, safetyPrefix+"manual")); err != nil {
+		t.Errorf("unrelated directory deleted: %v", err)
+	}
+}
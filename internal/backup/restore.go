@@ -0,0 +1,410 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hostathome/cli/internal/server"
+)
+
+// safetyPrefix names the directory current data is moved to before a restore
+const safetyPrefix = "pre-restore-"
+
+// List returns the backups of a server instance, newest first
+func List(instance string) ([]*Info, error) {
+	dir, err := Dir(instance)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []*Info
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), metadataExt) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var info Info
+		if err := json.Unmarshal(data, &info); err != nil || info.Name == "" {
+			continue
+		}
+		// Metadata is only trusted for archives in its own directory
+		info.Name = filepath.Base(info.Name)
+		info.Instance = instance
+		info.dir = dir
+		backups = append(backups, &info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Created.After(backups[j].Created) })
+	return backups, nil
+}
+
+// SafetyCopy is the data and configs a restore moved aside
+type SafetyCopy struct {
+	Name    string    `json:"name" yaml:"name"`
+	Path    string    `json:"path" yaml:"path"`
+	Created time.Time `json:"created" yaml:"created"`
+	Size    int64     `json:"size" yaml:"size"`
+}
+
+// SafetyCopies returns the safety copies of a server instance, newest first
+func SafetyCopies(instance string) ([]*SafetyCopy, error) {
+	dir, err := Dir(instance)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var copies []*SafetyCopy
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), safetyPrefix) {
+			continue
+		}
+		created, err := time.Parse(timestampFormat, strings.TrimPrefix(e.Name(), safetyPrefix))
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		size, err := dirSize(path)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, &SafetyCopy{Name: e.Name(), Path: path, Created: created, Size: size})
+	}
+
+	sort.Slice(copies, func(i, j int) bool { return copies[i].Created.After(copies[j].Created) })
+	return copies, nil
+}
+
+// dirSize returns the total size of the regular files under dir
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// Find returns a backup by archive name (with or without extension), or the newest for "latest"
+func Find(instance, name string) (*Info, error) {
+	backups, err := List(instance)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no backups found for %s", instance)
+	}
+	if name == "latest" {
+		return backups[0], nil
+	}
+
+	name = strings.TrimSuffix(filepath.Base(name), archiveExt)
+	for _, b := range backups {
+		if strings.TrimSuffix(b.Name, archiveExt) == name {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("backup '%s' not found", name)
+}
+
+// Verify checks that the archive exists and matches its recorded checksum
+func (i *Info) Verify() error {
+	f, err := os.Open(i.Path())
+	if err != nil {
+		return fmt.Errorf("archive not readable: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != i.SHA256 {
+		return fmt.Errorf("checksum mismatch (expected %s, got %s)", i.SHA256, sum)
+	}
+	return nil
+}
+
+// Restore replaces the data and configs directories of the backup's server
+// instance with the archive contents. The current directories are moved into
+// a safety copy first, whose path is returned; they are put back if
+// extraction fails.
+func Restore(info *Info) (string, error) {
+	serverDir := server.Dir(info.Instance)
+	safetyDir := filepath.Join(info.dir, safetyPrefix+time.Now().UTC().Format(timestampFormat))
+	if err := os.MkdirAll(safetyDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create safety copy directory: %w", err)
+	}
+
+	var moved []string
+	for _, dir := range backedUpDirs {
+		src := filepath.Join(serverDir, dir)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+		if err := moveDir(src, filepath.Join(safetyDir, dir)); err != nil {
+			rollback(serverDir, safetyDir, moved)
+			return "", fmt.Errorf("failed to move %s aside: %w", dir, err)
+		}
+		moved = append(moved, dir)
+	}
+
+	if err := extractArchive(info.Path(), serverDir); err != nil {
+		for _, dir := range backedUpDirs {
+			os.RemoveAll(filepath.Join(serverDir, dir))
+		}
+		rollback(serverDir, safetyDir, moved)
+		return "", fmt.Errorf("failed to extract backup: %w", err)
+	}
+
+	// Make sure mount directories exist even if the archive lacked one
+	for _, dir := range backedUpDirs {
+		if err := os.MkdirAll(filepath.Join(serverDir, dir), 0755); err != nil {
+			return safetyDir, err
+		}
+	}
+
+	return safetyDir, nil
+}
+
+// rollback moves directories from the safety copy back into the server directory
+func rollback(serverDir, safetyDir string, moved []string) {
+	for _, dir := range moved {
+		_ = moveDir(filepath.Join(safetyDir, dir), filepath.Join(serverDir, dir))
+	}
+	_ = os.Remove(safetyDir)
+}
+
+// moveDir moves a directory, copying it if src and dst are on different
+// filesystems (the backup directory is usually not next to the server)
+func moveDir(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	// Only copy into a new directory, never over existing data
+	if _, statErr := os.Lstat(dst); !os.IsNotExist(statErr) {
+		return err
+	}
+	if err := copyDir(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyDir copies a directory tree, keeping permissions and symlinks
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			// Sockets, pipes and devices aren't world data
+			return nil
+		}
+	})
+}
+
+// copyFile copies a regular file
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// extractArchive extracts a backup archive into serverDir. Only entries under
+// the backed up directories are accepted, and nothing is written outside serverDir.
+func extractArchive(archivePath, serverDir string) error {
+	root, err := filepath.Abs(serverDir)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target, err := entryPath(root, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkParent(root, target); err != nil {
+			return err
+		}
+		// An earlier entry may have put a symlink here, never write through it
+		if fi, err := os.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry %s would overwrite a symlink", header.Name)
+		}
+
+		mode := os.FileMode(header.Mode).Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+			// Chtimes would follow the link
+			continue
+		default:
+			// Skip devices, hard links and other special files
+			continue
+		}
+		_ = os.Chtimes(target, header.ModTime, header.ModTime)
+	}
+}
+
+// entryPath returns the extraction path of an archive entry, rejecting entries
+// outside the backed up directories
+func entryPath(root, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %s escapes the server directory", name)
+	}
+
+	top := strings.SplitN(filepath.ToSlash(clean), "/", 2)[0]
+	allowed := false
+	for _, dir := range backedUpDirs {
+		if top == dir {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("unexpected archive entry %s", name)
+	}
+
+	return filepath.Join(root, clean), nil
+}
+
+// checkParent ensures that the existing parent directory of target, after
+// resolving symlinks, is still inside root
+func checkParent(root, target string) error {
+	parent := filepath.Dir(target)
+	for {
+		if _, err := os.Lstat(parent); err == nil {
+			break
+		}
+		parent = filepath.Dir(parent)
+	}
+
+	resolved, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return err
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	if resolved != resolvedRoot && !strings.HasPrefix(resolved, resolvedRoot+string(filepath.Separator)) {
+		return fmt.Errorf("archive entry %s escapes the server directory", target)
+	}
+	return nil
+}
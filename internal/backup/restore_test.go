@@ -0,0 +1,168 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// entry is a file, directory or symlink in a test archive
+type entry struct {
+	name     string
+	typeflag byte
+	body     string
+	link     string
+}
+
+func file(name, body string) entry { return entry{name: name, typeflag: tar.TypeReg, body: body} }
+func dir(name string) entry        { return entry{name: name, typeflag: tar.TypeDir} }
+func symlink(name, link string) entry {
+	return entry{name: name, typeflag: tar.TypeSymlink, link: link}
+}
+
+// buildArchive writes the entries to a gzip-compressed tarball
+func buildArchive(t *testing.T, path string, entries []entry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.link,
+			Mode:     0644,
+			Size:     int64(len(e.body)),
+			ModTime:  time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC),
+		}
+		if e.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		// outside is relative to the directory containing the server directory
+		outside string
+		wantErr string
+	}{
+		{"parent", []entry{file("../evil", "x")}, "evil", "escapes"},
+		{"parent after data", []entry{file("data/../../evil", "x")}, "evil", "escapes"},
+		{"parent inside data", []entry{file("data/../configs/ok", "x")}, "", ""},
+		{"absolute", []entry{file("/tmp/hostathome-evil", "x")}, "", "escapes"},
+		{"other directory", []entry{file("other/file", "x")}, "server/other/file", "unexpected"},
+		{"bare parent", []entry{dir("..")}, "", "escapes"},
+		{
+			"write through absolute symlink",
+			[]entry{dir("data"), symlink("data/link", "OUTSIDE"), file("data/link/evil", "x")},
+			"outside/evil",
+			"escapes",
+		},
+		{
+			"write through relative symlink",
+			[]entry{dir("data"), symlink("data/up", "../.."), file("data/up/evil", "x")},
+			"evil",
+			"escapes",
+		},
+		{
+			"directory through symlink",
+			[]entry{symlink("configs/up", "../.."), dir("configs/up/evil")},
+			"evil",
+			"escapes",
+		},
+		{
+			"overwrite symlink",
+			[]entry{symlink("data/target", "OUTSIDE/target"), file("data/target", "x")},
+			"outside/target",
+			"symlink",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			serverDir := filepath.Join(base, "server")
+			outsideDir := filepath.Join(base, "outside")
+			for _, d := range []string{serverDir, outsideDir} {
+				if err := os.MkdirAll(d, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries := make([]entry, len(tt.entries))
+			for i, e := range tt.entries {
+				e.link = strings.Replace(e.link, "OUTSIDE", outsideDir, 1)
+				entries[i] = e
+			}
+			archive := filepath.Join(base, "backup.tar.gz")
+			buildArchive(t, archive, entries)
+
+			err := extractArchive(archive, serverDir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("extractArchive() error = %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("extractArchive() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if tt.outside != "" {
+				if _, err := os.Lstat(filepath.Join(base, tt.outside)); !os.IsNotExist(err) {
+					t.Errorf("%s was created", tt.outside)
+				}
+			}
+			if entries, _ := os.ReadDir(outsideDir); len(entries) > 0 {
+				t.Errorf("files written outside the server directory: %v", entries)
+			}
+		})
+	}
+}
+
+func TestExtractArchiveValid(t *testing.T) {
+	serverDir := t.TempDir()
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	buildArchive(t, archive, []entry{
+		dir("data"),
+		file("data/world/level.dat", "level"),
+		symlink("data/latest", "world"),
+		file("configs/server.properties", "motd=hi"),
+	})
+
+	if err := extractArchive(archive, serverDir); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{
+		"data/world/level.dat":      "level",
+		"data/latest/level.dat":     "level",
+		"configs/server.properties": "motd=hi",
+	} {
+		got, err := os.ReadFile(filepath.Join(serverDir, path))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+		} else if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -1,15 +1,95 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	appName     = "hostathome"
 	cacheSubdir = "cache/registry"
+	configFile  = "config.yaml"
+	keysSubdir  = "trusted_keys"
+	backupsDir  = "backups"
+
+	// RegistryEnv overrides the registries from the config file (comma-separated)
+	RegistryEnv = "HOSTATHOME_REGISTRY"
+	// DefaultRegistryName is the name of the public HostAtHome registry
+	DefaultRegistryName = "hostathome"
 )
 
+var registryNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Config holds global CLI settings read from ~/.hostathome/config.yaml
+type Config struct {
+	// Registry is a single game registry location: an https:// URL, a file:// URL or a directory path
+	Registry string `yaml:"registry"`
+	// Registries is an ordered list of registries; earlier entries shadow later ones
+	Registries []RegistryEntry `yaml:"registries"`
+	// CacheTTL is how long cached registry files are used before checking for
+	// updates, as a duration such as "30m" or "24h"
+	CacheTTL string `yaml:"cache_ttl,omitempty"`
+	// Offline uses only cached game definitions and local images
+	Offline bool `yaml:"offline,omitempty"`
+	// VerifySignatures refuses remote registry files without a valid minisign
+	// signature by a key in TrustedKeysDir
+	VerifySignatures bool `yaml:"verify_signatures,omitempty"`
+}
+
+// RegistryEntry is a named registry location. An empty URL means the public registry.
+type RegistryEntry struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// RegistryEntries returns the registries from the config file in priority order,
+// or nil if none are configured
+func (c *Config) RegistryEntries() []RegistryEntry {
+	if len(c.Registries) > 0 {
+		return c.Registries
+	}
+	if c.Registry != "" {
+		return []RegistryEntry{ParseRegistryEntry(c.Registry)}
+	}
+	return nil
+}
+
+// CacheTTLDuration parses CacheTTL. ok is false if it isn't set.
+func (c *Config) CacheTTLDuration() (ttl time.Duration, ok bool, err error) {
+	if c.CacheTTL == "" {
+		return 0, false, nil
+	}
+	ttl, err = time.ParseDuration(c.CacheTTL)
+	if err != nil || ttl < 0 {
+		return 0, false, fmt.Errorf("invalid cache_ttl %q in %s (use a duration such as 30m or 24h)", c.CacheTTL, configFile)
+	}
+	return ttl, true, nil
+}
+
+// ParseRegistryEntry parses "name=location" or a bare location. Bare locations
+// are named after the URL host or the directory name.
+func ParseRegistryEntry(value string) RegistryEntry {
+	if name, location, ok := strings.Cut(value, "="); ok && registryNameRe.MatchString(name) {
+		return RegistryEntry{Name: name, URL: location}
+	}
+
+	name := value
+	if u, err := url.Parse(value); err == nil && u.Host != "" {
+		name = u.Host
+	} else {
+		name = filepath.Base(strings.TrimPrefix(value, "file://"))
+	}
+	return RegistryEntry{Name: name, URL: value}
+}
+
 // GetCacheDir returns the cache directory for registry files
 func GetCacheDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -39,3 +119,46 @@ func GetConfigDir() (string, error) {
 
 	return configDir, nil
 }
+
+// TrustedKeysDir returns the directory of minisign public keys (*.pub) trusted
+// to sign registry files
+func TrustedKeysDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, keysSubdir), nil
+}
+
+// BackupsDir returns the directory server backups are kept in,
+// ~/.hostathome/backups. Keeping them out of the server directories means
+// uninstalling a server doesn't delete its backups.
+func BackupsDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, backupsDir), nil
+}
+
+// Load reads the global config file. A missing file yields an empty Config.
+func Load() (*Config, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	data, err := os.ReadFile(filepath.Join(configDir, configFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", configFile, err)
+	}
+	return cfg, nil
+}
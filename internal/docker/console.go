@@ -0,0 +1,240 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/moby/term"
+)
+
+// DefaultDetachKeys is the key sequence that detaches from a console without stopping the server
+const DefaultDetachKeys = "ctrl-p,ctrl-q"
+
+// ConsoleOptions controls how AttachConsole connects to a server
+type ConsoleOptions struct {
+	// DetachKeys is the detach key sequence in Docker format (e.g. "ctrl-p,ctrl-q")
+	DetachKeys string
+	// Tail is the number of recent log lines to print before attaching
+	Tail int
+}
+
+// ConsoleInfo describes the console of a running server instance
+type ConsoleInfo struct {
+	// TTY is true if the container has a terminal; detach keys only work with one
+	TTY bool
+	// Stdin is true if the container accepts console input
+	Stdin bool
+}
+
+// InspectConsole reports how the console of a running server instance can be used
+func InspectConsole(instance string) (*ConsoleInfo, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return nil, fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := findContainer(ctx, cli, instance, false)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("container %s not found or not running", containerPrefix+instance)
+	}
+
+	info, err := cli.ContainerInspect(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsoleInfo{TTY: info.Config.Tty, Stdin: info.Config.OpenStdin}, nil
+}
+
+// AttachConsole connects the terminal to a running server instance's console
+// until Ctrl+C or the detach keys (containers with a TTY only) are pressed, or
+// the server exits. It reports whether the server is still running afterwards.
+func AttachConsole(instance string, opts ConsoleOptions) (bool, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return false, fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	cli, err := getClient()
+	if err != nil {
+		return false, err
+	}
+
+	// The console stays open indefinitely, only the lookups use a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	c, err := findContainer(ctx, cli, instance, false)
+	if err != nil {
+		return false, err
+	}
+	if c == nil {
+		return false, fmt.Errorf("container %s not found or not running", containerPrefix+instance)
+	}
+
+	info, err := cli.ContainerInspect(ctx, c.ID)
+	if err != nil {
+		return false, err
+	}
+	tty := info.Config.Tty
+
+	// Show recent output so the console has some context
+	if opts.Tail > 0 {
+		logs, err := cli.ContainerLogs(ctx, c.ID, container.LogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Tail:       strconv.Itoa(opts.Tail),
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to read logs: %w", err)
+		}
+		err = copyOutput(os.Stdout, logs, tty)
+		logs.Close()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	attachCtx, stopAttach := context.WithCancel(context.Background())
+	defer stopAttach()
+
+	resp, err := cli.ContainerAttach(attachCtx, c.ID, container.AttachOptions{
+		Stream:     true,
+		Stdin:      info.Config.OpenStdin,
+		Stdout:     true,
+		Stderr:     true,
+		DetachKeys: opts.DetachKeys,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to attach: %w", err)
+	}
+	defer resp.Close()
+
+	inFd, inIsTerm := term.GetFdInfo(os.Stdin)
+	if tty && inIsTerm {
+		state, err := term.SetRawTerminal(inFd)
+		if err != nil {
+			return false, fmt.Errorf("failed to set raw terminal: %w", err)
+		}
+		defer term.RestoreTerminal(inFd, state)
+
+		resize := func() { resizeConsole(cli, c.ID, inFd) }
+		resize()
+		watchResize(attachCtx, resize)
+	}
+
+	// Ctrl+C detaches rather than reaching the server. Without a TTY it is a
+	// signal to this process; in a raw terminal it arrives as input.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	go func() {
+		select {
+		case <-sigs:
+			resp.Close()
+		case <-attachCtx.Done():
+		}
+	}()
+
+	if info.Config.OpenStdin {
+		go func() {
+			if interrupted, _ := copyInput(resp.Conn, os.Stdin); interrupted {
+				resp.Close()
+				return
+			}
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	// Output ends when the user detaches or the server exits
+	_ = copyOutput(os.Stdout, resp.Reader, tty)
+
+	stateCtx, stateCancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer stateCancel()
+	after, err := cli.ContainerInspect(stateCtx, c.ID)
+	if err != nil {
+		return false, err
+	}
+	return after.State != nil && after.State.Running, nil
+}
+
+// Control characters a terminal turns into signals for its foreground process
+const (
+	keyInterrupt = 0x03 // Ctrl+C, SIGINT
+	keySuspend   = 0x1a // Ctrl+Z, SIGTSTP
+	keyQuit      = 0x1c // Ctrl+\, SIGQUIT
+)
+
+// copyInput forwards console input to w until r ends or Ctrl+C is pressed, and
+// reports whether it was. Keys that would signal the server through its TTY
+// are never forwarded, so the console can't interrupt or suspend the server.
+func copyInput(w io.Writer, r io.Reader) (bool, error) {
+	buf := make([]byte, 1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			input := buf[:n]
+			interrupted := false
+			if i := bytes.IndexByte(input, keyInterrupt); i >= 0 {
+				input, interrupted = input[:i], true
+			}
+			input = bytes.ReplaceAll(input, []byte{keySuspend}, nil)
+			input = bytes.ReplaceAll(input, []byte{keyQuit}, nil)
+			if len(input) > 0 {
+				if _, err := w.Write(input); err != nil {
+					return false, err
+				}
+			}
+			if interrupted {
+				return true, nil
+			}
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// copyOutput copies container output, demultiplexing stdout and stderr for containers without a TTY
+func copyOutput(w io.Writer, r io.Reader, tty bool) error {
+	if tty {
+		_, err := io.Copy(w, r)
+		return err
+	}
+	_, err := stdcopy.StdCopy(w, os.Stderr, r)
+	return err
+}
+
+// resizeConsole matches the container's TTY size to the local terminal
+func resizeConsole(cli *client.Client, containerID string, fd uintptr) {
+	ws, err := term.GetWinsize(fd)
+	if err != nil || ws.Height == 0 || ws.Width == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+	_ = cli.ContainerResize(ctx, containerID, container.ResizeOptions{
+		Height: uint(ws.Height),
+		Width:  uint(ws.Width),
+	})
+}
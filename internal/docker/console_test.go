@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCopyInput(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        string
+		interrupted bool
+	}{
+		{"plain", "say hi\n", "say hi\n", false},
+		{"empty", "", "", false},
+		{"interrupt detaches", "list\n\x03say never\n", "list\n", true},
+		{"interrupt first", "\x03", "", true},
+		{"suspend and quit dropped", "sa\x1ay\x1c hi\n", "say hi\n", false},
+		{"detach keys forwarded", "\x10\x11", "\x10\x11", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One byte at a time, as typed
+			var out bytes.Buffer
+			interrupted, err := copyInput(&out, iotest.OneByteReader(strings.NewReader(tt.input)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want || interrupted != tt.interrupted {
+				t.Errorf("copyInput() = %q, %v, want %q, %v", out.String(), interrupted, tt.want, tt.interrupted)
+			}
+
+			// And all at once, as pasted
+			out.Reset()
+			interrupted, _ = copyInput(&out, strings.NewReader(tt.input))
+			if out.String() != tt.want || interrupted != tt.interrupted {
+				t.Errorf("copyInput() in one read = %q, %v, want %q, %v", out.String(), interrupted, tt.want, tt.interrupted)
+			}
+		})
+	}
+}
+
+func TestCopyInputErrors(t *testing.T) {
+	errRead := errors.New("read failed")
+	if _, err := copyInput(io.Discard, iotest.ErrReader(errRead)); !errors.Is(err, errRead) {
+		t.Errorf("copyInput() error = %v, want %v", err, errRead)
+	}
+	pr, pw := io.Pipe()
+	pr.Close()
+	if _, err := copyInput(pw, strings.NewReader("x")); err == nil {
+		t.Error("copyInput() ignored a write error")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -17,18 +18,28 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
 	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
 )
 
 const (
 	containerPrefix   = "hostathome-"
 	dockerOpTimeout   = 30  // seconds for container operations
 	dockerPullTimeout = 300 // seconds for image pull (5 minutes)
+	exitCodeKilled    = 137 // 128 + SIGKILL
 	minPort           = 1
 	maxPort           = 65535
 )
 
+const (
+	// defaultStopTimeout is Docker's grace period between the stop signal and SIGKILL
+	defaultStopTimeout = 10 * time.Second
+	// minStopGrace is the least time a server gets after the stop signal, even
+	// if its shutdown commands used up the timeout
+	minStopGrace = 5 * time.Second
+)
+
 var (
 	dockerClient *client.Client
 	clientOnce   sync.Once
@@ -73,12 +84,50 @@ func ValidatePort(port int, name string) error {
 	return nil
 }
 
-// ContainerStatus represents the status of a game container
+// findContainer returns the container for a server instance, or nil if none exists.
+// If all is false, only running containers are considered.
+func findContainer(ctx context.Context, cli *client.Client, instance string, all bool) (*types.Container, error) {
+	// Anchor the name filter, Docker matches names as substrings otherwise
+	containers, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     all,
+		Filters: filters.NewArgs(filters.Arg("name", "^/"+containerPrefix+instance+"$")),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, nil
+	}
+	return &containers[0], nil
+}
+
+// ContainerStatus represents the status of a game container. The tags are the
+// field names of structured status output and must stay stable.
 type ContainerStatus struct {
-	Game        string
-	Status      string
-	Ports       string
-	ContainerID string
+	Game     string `json:"game" yaml:"game"`
+	Instance string `json:"instance" yaml:"instance"`
+	Status   string `json:"status" yaml:"status"`
+	// Ports is a human-readable summary of PortBindings
+	Ports        string        `json:"-" yaml:"-"`
+	PortBindings []PortBinding `json:"ports" yaml:"ports"`
+	ContainerID  string        `json:"container_id" yaml:"container_id"`
+	// HostPorts maps game port names to the first host port they are published on
+	HostPorts map[string]int `json:"-" yaml:"-"`
+	// Health is "healthy", "unhealthy" or "starting", or "" without a healthcheck
+	Health string `json:"health,omitempty" yaml:"health,omitempty"`
+	// MemoryLimit, CPULimit and PidsLimit are the container's limits, 0 if unlimited
+	MemoryLimit int64   `json:"memory_limit,omitempty" yaml:"memory_limit,omitempty"`
+	CPULimit    float64 `json:"cpu_limit,omitempty" yaml:"cpu_limit,omitempty"`
+	PidsLimit   int64   `json:"pids_limit,omitempty" yaml:"pids_limit,omitempty"`
+}
+
+// PortBinding is a container port published on the host
+type PortBinding struct {
+	// Name is the game port name, if the container is labelled with it
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Host      int    `json:"host" yaml:"host"`
+	Container int    `json:"container" yaml:"container"`
+	Protocol  string `json:"protocol" yaml:"protocol"`
 }
 
 // PullImage pulls the Docker image for a game
@@ -102,13 +151,60 @@ func PullImage(imageName string) error {
 	return err
 }
 
-// CreateServerDirs creates the directory structure for a game server
-func CreateServerDirs(gameName string) error {
-	if err := ValidateGameName(gameName); err != nil {
-		return fmt.Errorf("invalid game name: %w", err)
+// ImageInfo describes a pulled image. The tags are the field names of
+// structured info output.
+type ImageInfo struct {
+	ID string `json:"id" yaml:"id"`
+	// Digest is the registry digest the image was pulled by, if known
+	Digest  string    `json:"digest,omitempty" yaml:"digest,omitempty"`
+	Size    int64     `json:"size" yaml:"size"`
+	Created time.Time `json:"created" yaml:"created"`
+}
+
+// InspectImage returns details of a local image, or nil if it hasn't been pulled
+func InspectImage(imageName string) (*ImageInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := cli.ImageInspectWithRaw(ctx, imageName)
+	if client.IsErrNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ImageInfo{ID: img.ID, Size: img.Size}
+	info.Created, _ = time.Parse(time.RFC3339Nano, img.Created)
+	// Repo digests look like "ghcr.io/hostathome/minecraft-server@sha256:..."
+	repo := imageName
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	for _, d := range img.RepoDigests {
+		name, digest, ok := strings.Cut(d, "@")
+		if !ok {
+			continue
+		}
+		if name == repo || info.Digest == "" {
+			info.Digest = digest
+		}
+	}
+	return info, nil
+}
+
+// CreateServerDirs creates the directory structure for a server instance
+func CreateServerDirs(instance string) error {
+	if err := ValidateGameName(instance); err != nil {
+		return fmt.Errorf("invalid instance name: %w", err)
 	}
 
-	baseDir := fmt.Sprintf("./%s-server", gameName)
+	baseDir := server.Dir(instance)
 	dirs := []string{
 		filepath.Join(baseDir, "data"),
 		filepath.Join(baseDir, "configs"),
@@ -122,10 +218,21 @@ func CreateServerDirs(gameName string) error {
 	return nil
 }
 
-// RunContainer starts a game server container
-func RunContainer(gameName string, game *registry.Game, devMode bool) error {
-	if err := ValidateGameName(gameName); err != nil {
-		return fmt.Errorf("invalid game name: %w", err)
+// RunOptions controls how RunContainer creates a container
+type RunOptions struct {
+	// DevMode uses a local image instead of pulling from the registry
+	DevMode bool
+	// AutoPorts moves conflicting host ports to the next free port instead of failing
+	AutoPorts bool
+	// Offline uses the previously pulled image instead of pulling it
+	Offline bool
+}
+
+// RunContainer starts the container for a server instance of a game and
+// returns the host ports it is published on
+func RunContainer(instance string, game *registry.Game, opts RunOptions) ([]registry.Port, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return nil, fmt.Errorf("invalid instance name: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
@@ -133,15 +240,15 @@ func RunContainer(gameName string, game *registry.Game, devMode bool) error {
 
 	cli, err := getClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	containerName := containerPrefix + gameName
+	containerName := containerPrefix + instance
 
 	// Create server directories (required for mounts to work)
-	absPath, err := filepath.Abs(fmt.Sprintf("./%s-server", gameName))
+	absPath, err := filepath.Abs(server.Dir(instance))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Create mount directories to avoid "bind source path does not exist" errors
@@ -151,25 +258,66 @@ func RunContainer(gameName string, game *registry.Game, devMode bool) error {
 	}
 	for _, dir := range mountDirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create mount directory %s: %w", dir, err)
+			return nil, fmt.Errorf("failed to create mount directory %s: %w", dir, err)
 		}
 	}
 
 	// Check if container already exists
-	containers, err := cli.ContainerList(ctx, container.ListOptions{
-		All:     true,
-		Filters: filters.NewArgs(filters.Arg("name", containerName)),
-	})
+	c, err := findContainer(ctx, cli, instance, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// If container exists, check if mount paths are valid
-	if len(containers) > 0 {
-		c := containers[0]
+	manifest, err := server.LoadManifest(instance)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := containerResources(game, manifest)
+	if err != nil {
+		return nil, err
+	}
+	envValues, err := server.LoadEnv(instance)
+	if err != nil {
+		return nil, err
+	}
+	// A missing required variable only matters when a container is created
+	env, envErr := containerEnv(game, envValues)
+
+	// If container exists, check if mount paths, ports and limits are still valid
+	if c != nil {
+		current, err := publishedPorts(ctx, cli, c.ID, game)
+		if err != nil {
+			return nil, err
+		}
+		portsChanged := pinnedPortsDiffer(manifest.Ports, current)
+
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		resourcesChanged := resourcesDiffer(info.HostConfig.Resources, resources)
+		envChanged := envErr == nil && c.Labels[envLabel] != envHash(env)
+		consoleChanged := info.Config.Tty != game.TTY()
+
 		if c.State == "running" {
-			fmt.Printf("Container %s is already running\n", containerName)
-			return nil
+			if portsChanged {
+				return nil, fmt.Errorf("container %s is running with different ports, stop it first to apply the new ports", containerName)
+			}
+			if envChanged {
+				return nil, fmt.Errorf("container %s is running with different environment variables, stop it first to apply them", containerName)
+			}
+			if resourcesChanged {
+				// Limits can be changed live, except removing one, which needs a new container
+				if limitsRemoved(info.HostConfig.Resources, resources) {
+					return nil, fmt.Errorf("container %s is running with resource limits that were removed, stop it first to apply them", containerName)
+				}
+				if _, err := cli.ContainerUpdate(ctx, c.ID, container.UpdateConfig{Resources: resources}); err != nil {
+					return nil, fmt.Errorf("failed to update resource limits of %s, stop it first to apply them: %w", containerName, err)
+				}
+				ui.Info("Updated resource limits of %s", containerName)
+			}
+			ui.Info("Container %s is already running", containerName)
+			return current, nil
 		}
 
 		// Check if mount paths exist
@@ -183,86 +331,116 @@ func RunContainer(gameName string, game *registry.Game, devMode bool) error {
 			}
 		}
 
-		// If all mounts are valid, start the container
-		if allMountsValid {
-			fmt.Printf("Starting existing container %s...\n", containerName)
-			return cli.ContainerStart(ctx, c.ID, container.StartOptions{})
+		// If all mounts are valid and settings unchanged, start the container
+		if allMountsValid && !portsChanged && !resourcesChanged && !envChanged && !consoleChanged {
+			if err := checkContainerPorts(ctx, cli, c.ID, instance); err != nil {
+				return nil, err
+			}
+			ui.Step("Starting existing container %s", containerName)
+			if err := cli.ContainerStart(ctx, c.ID, container.StartOptions{}); err != nil {
+				return nil, err
+			}
+			return current, nil
 		}
 
-		// Mount paths don't exist - remove stale container and recreate
-		fmt.Printf("Container mount paths are invalid, recreating...\n")
+		// Mount paths don't exist or settings changed - remove stale container and recreate
+		if portsChanged {
+			ui.Info("Port settings changed, recreating...")
+		} else if resourcesChanged {
+			ui.Info("Resource limits changed, recreating...")
+		} else if envChanged {
+			ui.Info("Environment variables changed, recreating...")
+		} else if consoleChanged {
+			ui.Info("Console settings changed, recreating...")
+		} else {
+			ui.Info("Container mount paths are invalid, recreating...")
+		}
 		if err := cli.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true}); err != nil {
-			return fmt.Errorf("failed to remove stale container: %w", err)
+			return nil, fmt.Errorf("failed to remove stale container: %w", err)
 		}
 	}
 
+	if envErr != nil {
+		return nil, envErr
+	}
+
 	// In dev mode, skip image pull and verify local image exists
-	if devMode {
-		fmt.Println("🔧 Dev mode: skipping image pull, using local image")
+	if opts.DevMode {
+		ui.Println("🔧 Dev mode: skipping image pull, using local image")
 		images, err := cli.ImageList(ctx, image.ListOptions{
 			Filters: filters.NewArgs(filters.Arg("reference", game.Image)),
 		})
 		if err != nil || len(images) == 0 {
-			return fmt.Errorf("local image %s not found. Build it first with: docker build -t %s .", game.Image, game.Image)
+			return nil, fmt.Errorf("local image %s not found. Build it first with: docker build -t %s .", game.Image, game.Image)
+		}
+	} else if opts.Offline {
+		if _, _, err := cli.ImageInspectWithRaw(ctx, game.Image); client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("image %s has not been pulled and can't be in offline mode", game.Image)
+		} else if err != nil {
+			return nil, err
 		}
 	} else {
 		// Normal mode: pull the image from registry
 		if err := PullImage(game.Image); err != nil {
-			return fmt.Errorf("failed to pull image: %w", err)
+			return nil, fmt.Errorf("failed to pull image: %w", err)
 		}
 	}
 
 	// Validate port mappings
-	if game.Ports.Player > 0 {
-		if err := ValidatePort(game.Ports.Player, "external player"); err != nil {
-			return err
+	for _, p := range game.Ports {
+		if p.Internal > 0 {
+			if err := ValidatePort(p.Internal+p.Count()-1, "internal "+p.Name); err != nil {
+				return nil, err
+			}
 		}
-	}
-	if game.Ports.RCON > 0 {
-		if err := ValidatePort(game.Ports.RCON, "external RCON"); err != nil {
-			return err
+		if p.Host > 0 {
+			if err := ValidatePort(p.Host+p.Count()-1, "external "+p.Name); err != nil {
+				return nil, err
+			}
 		}
 	}
-	if game.InternalPorts.Player > 0 {
-		if err := ValidatePort(game.InternalPorts.Player, "internal player"); err != nil {
-			return err
-		}
+
+	healthcheck, err := healthConfig(game)
+	if err != nil {
+		return nil, fmt.Errorf("invalid game definition: %w", err)
 	}
-	if game.InternalPorts.RCON > 0 {
-		if err := ValidatePort(game.InternalPorts.RCON, "internal RCON"); err != nil {
-			return err
-		}
+
+	// Check host ports for conflicts and persist the chosen ones
+	hostPorts, err := allocatePorts(ctx, cli, instance, game, opts.AutoPorts)
+	if err != nil {
+		return nil, err
 	}
 
 	// Port mappings
-	portBindings := nat.PortMap{}
-	exposedPorts := nat.PortSet{}
+	exposedPorts, bindings, portLabels := portBindings(hostPorts)
 
-	if game.InternalPorts.Player > 0 && game.Ports.Player > 0 {
-		playerProto := game.Protocols.DefaultProtocol("player")
-		internalPort := nat.Port(fmt.Sprintf("%d/%s", game.InternalPorts.Player, playerProto))
-		portBindings[internalPort] = []nat.PortBinding{{HostPort: fmt.Sprintf("%d", game.Ports.Player)}}
-		exposedPorts[internalPort] = struct{}{}
+	labels := map[string]string{
+		"hostathome":          "true",
+		"hostathome.game":     game.Name,
+		"hostathome.instance": instance,
 	}
-
-	if game.InternalPorts.RCON > 0 && game.Ports.RCON > 0 {
-		rconProto := game.Protocols.DefaultProtocol("rcon")
-		internalPort := nat.Port(fmt.Sprintf("%d/%s", game.InternalPorts.RCON, rconProto))
-		portBindings[internalPort] = []nat.PortBinding{{HostPort: fmt.Sprintf("%d", game.Ports.RCON)}}
-		exposedPorts[internalPort] = struct{}{}
+	for k, v := range portLabels {
+		labels[k] = v
+	}
+	if hash := envHash(env); hash != "" {
+		labels[envLabel] = hash
 	}
 
 	config := &container.Config{
 		Image:        game.Image,
 		ExposedPorts: exposedPorts,
-		Labels: map[string]string{
-			"hostathome":      "true",
-			"hostathome.game": gameName,
-		},
+		Labels:       labels,
+		Healthcheck:  healthcheck,
+		Env:          env,
+		// Keep console input open so `hostathome console` can attach to it. A
+		// TTY changes the log format, so only games that need one get it.
+		OpenStdin: true,
+		Tty:       game.TTY(),
 	}
 
 	hostConfig := &container.HostConfig{
-		PortBindings: portBindings,
+		PortBindings: bindings,
+		Resources:    resources,
 		Mounts: []mount.Mount{
 			{
 				Type:   mount.TypeBind,
@@ -282,16 +460,41 @@ func RunContainer(gameName string, game *registry.Game, devMode bool) error {
 
 	resp, err := cli.ContainerCreate(ctx, config, hostConfig, nil, nil, containerName)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return nil, err
 	}
+	return hostPorts, nil
+}
+
+// StopOptions controls how a server instance's container is stopped
+type StopOptions struct {
+	// Timeout is how long the server gets to exit after the stop signal before
+	// it is killed. Zero uses Docker's default of 10 seconds.
+	Timeout time.Duration
+	// Since is when the server was told to shut down, e.g. by an RCON stop
+	// command. The timeout then counts from Since, and the stop signal only
+	// gets what is left of it.
+	Since time.Time
+	// ContainerID is the container found before the shutdown commands were
+	// sent, so a server that already exited on its own is still stopped.
+	// Empty looks up the running container.
+	ContainerID string
+}
 
-	return cli.ContainerStart(ctx, resp.ID, container.StartOptions{})
+// StopResult reports how a container stopped
+type StopResult struct {
+	ExitCode int
+	// Killed is true if the server didn't exit in time and was killed
+	Killed bool
 }
 
-// StopContainer stops a game server container
-func StopContainer(gameName string) error {
-	if err := ValidateGameName(gameName); err != nil {
-		return fmt.Errorf("invalid game name: %w", err)
+// StopContainer stops a server instance's running container
+func StopContainer(instance string, opts StopOptions) (*StopResult, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return nil, fmt.Errorf("invalid instance name: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
@@ -299,29 +502,77 @@ func StopContainer(gameName string) error {
 
 	cli, err := getClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	containerName := containerPrefix + gameName
+	if opts.ContainerID != "" {
+		return stopContainer(cli, opts.ContainerID, opts)
+	}
 
-	containers, err := cli.ContainerList(ctx, container.ListOptions{
-		Filters: filters.NewArgs(filters.Arg("name", containerName)),
-	})
+	c, err := findContainer(ctx, cli, instance, false)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if len(containers) == 0 {
-		return fmt.Errorf("container %s not found or not running", containerName)
+	if c == nil {
+		return nil, fmt.Errorf("container %s not found or not running", containerPrefix+instance)
 	}
 
-	return cli.ContainerStop(ctx, containers[0].ID, container.StopOptions{})
+	return stopContainer(cli, c.ID, opts)
 }
 
-// RestartContainer restarts a game server container
-func RestartContainer(gameName string) error {
-	if err := ValidateGameName(gameName); err != nil {
-		return fmt.Errorf("invalid game name: %w", err)
+// stopContainer stops a container and reports whether it had to be killed.
+// A server that was told to shut down is stopped through Docker right away
+// rather than left to exit on its own: the unless-stopped restart policy would
+// start it again, and only a manual stop cancels that.
+func stopContainer(cli *client.Client, containerID string, opts StopOptions) (*StopResult, error) {
+	grace := stopGrace(opts, time.Now())
+	stopOpts := container.StopOptions{}
+	if grace > 0 {
+		secs := int(grace / time.Second)
+		stopOpts.Timeout = &secs
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), max(grace, defaultStopTimeout)+dockerOpTimeout*time.Second)
+	defer cancel()
+
+	if err := cli.ContainerStop(ctx, containerID, stopOpts); err != nil {
+		return nil, err
+	}
+
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	result := &StopResult{}
+	if info.State != nil {
+		result.ExitCode = info.State.ExitCode
+		result.Killed = info.State.ExitCode == exitCodeKilled && !info.State.OOMKilled
+	}
+	return result, nil
+}
+
+// stopGrace returns how long a container gets between the stop signal and
+// SIGKILL, in whole seconds, or 0 for Docker's default. A server told to shut
+// down at opts.Since gets what is left of the timeout, but never less than
+// minStopGrace (or the timeout, if shorter) so it isn't killed outright.
+func stopGrace(opts StopOptions, now time.Time) time.Duration {
+	timeout := opts.Timeout
+	if opts.Since.IsZero() {
+		return timeout.Round(time.Second)
+	}
+	if timeout <= 0 {
+		timeout = defaultStopTimeout
+	}
+	grace := max(timeout-now.Sub(opts.Since), min(minStopGrace, timeout))
+	// Round up, Docker only takes whole seconds
+	return (grace + time.Second - 1).Truncate(time.Second)
+}
+
+// StartContainer starts a server instance's existing container
+func StartContainer(instance string) error {
+	if err := ValidateGameName(instance); err != nil {
+		return fmt.Errorf("invalid instance name: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
@@ -332,27 +583,22 @@ func RestartContainer(gameName string) error {
 		return err
 	}
 
-	containerName := containerPrefix + gameName
-
-	containers, err := cli.ContainerList(ctx, container.ListOptions{
-		All:     true,
-		Filters: filters.NewArgs(filters.Arg("name", containerName)),
-	})
+	c, err := findContainer(ctx, cli, instance, true)
 	if err != nil {
 		return err
 	}
 
-	if len(containers) == 0 {
-		return fmt.Errorf("container %s not found", containerName)
+	if c == nil {
+		return fmt.Errorf("container %s not found", containerPrefix+instance)
 	}
 
-	return cli.ContainerRestart(ctx, containers[0].ID, container.StopOptions{})
+	return cli.ContainerStart(ctx, c.ID, container.StartOptions{})
 }
 
-// RemoveContainer removes a game server container but keeps the data
-func RemoveContainer(gameName string) error {
-	if err := ValidateGameName(gameName); err != nil {
-		return fmt.Errorf("invalid game name: %w", err)
+// PauseContainer freezes all processes in a server instance's running container
+func PauseContainer(instance string) error {
+	if err := ValidateGameName(instance); err != nil {
+		return fmt.Errorf("invalid instance name: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
@@ -363,31 +609,187 @@ func RemoveContainer(gameName string) error {
 		return err
 	}
 
-	containerName := containerPrefix + gameName
+	c, err := findContainer(ctx, cli, instance, false)
+	if err != nil {
+		return err
+	}
 
-	containers, err := cli.ContainerList(ctx, container.ListOptions{
-		All:     true,
-		Filters: filters.NewArgs(filters.Arg("name", containerName)),
-	})
+	if c == nil {
+		return fmt.Errorf("container %s not found or not running", containerPrefix+instance)
+	}
+
+	return cli.ContainerPause(ctx, c.ID)
+}
+
+// UnpauseContainer resumes a server instance's paused container
+func UnpauseContainer(instance string) error {
+	if err := ValidateGameName(instance); err != nil {
+		return fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
 	if err != nil {
 		return err
 	}
 
-	if len(containers) == 0 {
-		return fmt.Errorf("container %s not found", containerName)
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil {
+		return err
+	}
+
+	if c == nil {
+		return fmt.Errorf("container %s not found", containerPrefix+instance)
+	}
+
+	return cli.ContainerUnpause(ctx, c.ID)
+}
+
+// ContainerState returns the state of a server instance's container
+// (e.g. "running", "paused", "exited"), or "" if it has no container
+func ContainerState(instance string) (string, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return "", fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return "", err
+	}
+
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil || c == nil {
+		return "", err
+	}
+	return c.State, nil
+}
+
+// ContainerGame returns the game a server instance's container was created
+// for, or "" if it has no container or the container predates the label
+func ContainerGame(instance string) (string, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return "", fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return "", err
+	}
+
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil || c == nil {
+		return "", err
+	}
+	return c.Labels["hostathome.game"], nil
+}
+
+// RunningContainerID returns the ID of a server instance's running container,
+// or "" if it isn't running
+func RunningContainerID(instance string) (string, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return "", fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return "", err
+	}
+
+	c, err := findContainer(ctx, cli, instance, false)
+	if err != nil || c == nil {
+		return "", err
+	}
+	return c.ID, nil
+}
+
+// stoppable reports whether a container in state needs stopping. A server that
+// exited after its shutdown commands may already be restarting.
+func stoppable(state string) bool {
+	return state == "running" || state == "restarting"
+}
+
+// RestartContainer stops a server instance's container if it is running and starts it again
+func RestartContainer(instance string, opts StopOptions) (*StopResult, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return nil, fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return nil, err
 	}
 
-	c := containers[0]
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil {
+		return nil, fmt.Errorf("container %s not found", containerPrefix+instance)
+	}
+
+	var result *StopResult
+	if stoppable(c.State) {
+		if result, err = stopContainer(cli, c.ID, opts); err != nil {
+			return nil, fmt.Errorf("failed to stop container: %w", err)
+		}
+	}
+
+	startCtx, startCancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer startCancel()
+	return result, cli.ContainerStart(startCtx, c.ID, container.StartOptions{})
+}
+
+// RemoveContainer removes a server instance's container but keeps the data.
+// The result is nil if the container wasn't running.
+func RemoveContainer(instance string, opts StopOptions) (*StopResult, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return nil, fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil {
+		return nil, fmt.Errorf("container %s not found", containerPrefix+instance)
+	}
 
 	// Stop if running
-	if c.State == "running" {
-		if err := cli.ContainerStop(ctx, c.ID, container.StopOptions{}); err != nil {
-			return fmt.Errorf("failed to stop container: %w", err)
+	var result *StopResult
+	if stoppable(c.State) {
+		if result, err = stopContainer(cli, c.ID, opts); err != nil {
+			return nil, fmt.Errorf("failed to stop container: %w", err)
 		}
 	}
 
 	// Remove container
-	return cli.ContainerRemove(ctx, c.ID, container.RemoveOptions{
+	removeCtx, removeCancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer removeCancel()
+	return result, cli.ContainerRemove(removeCtx, c.ID, container.RemoveOptions{
 		Force: true,
 	})
 }
@@ -408,7 +810,8 @@ func RemoveImage(imageName string) error {
 	return err
 }
 
-// GetStatus returns the status of game containers
+// GetStatus returns the status of all instances of a game, or of every
+// hostathome container if gameName is empty
 func GetStatus(gameName string) ([]ContainerStatus, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
 	defer cancel()
@@ -420,7 +823,7 @@ func GetStatus(gameName string) ([]ContainerStatus, error) {
 
 	filterArgs := filters.NewArgs(filters.Arg("label", "hostathome=true"))
 	if gameName != "" {
-		filterArgs.Add("name", containerPrefix+gameName)
+		filterArgs.Add("label", "hostathome.game="+gameName)
 	}
 
 	containers, err := cli.ContainerList(ctx, container.ListOptions{
@@ -433,31 +836,87 @@ func GetStatus(gameName string) ([]ContainerStatus, error) {
 
 	var statuses []ContainerStatus
 	for _, c := range containers {
+		// Containers created before instances existed have no instance label
+		instance := c.Labels["hostathome.instance"]
+		if instance == "" && len(c.Names) > 0 {
+			instance = strings.TrimPrefix(c.Names[0], "/"+containerPrefix)
+		}
 		game := c.Labels["hostathome.game"]
-		if game == "" && len(c.Names) > 0 {
-			game = strings.TrimPrefix(c.Names[0], "/"+containerPrefix)
+		if game == "" {
+			game = instance
 		}
 
-		ports := formatPorts(c.Ports)
+		ports := formatPorts(c.Ports, c.Labels)
+
+		// Limits aren't part of the container list
+		var limits container.Resources
+		if info, err := cli.ContainerInspect(ctx, c.ID); err == nil && info.HostConfig != nil {
+			limits = info.HostConfig.Resources
+		}
 
 		statuses = append(statuses, ContainerStatus{
-			Game:        game,
-			Status:      c.State,
-			Ports:       ports,
-			ContainerID: c.ID,
+			Game:         game,
+			Instance:     instance,
+			Status:       c.State,
+			Ports:        ports,
+			PortBindings: portBindingList(c.Ports, c.Labels),
+			ContainerID:  c.ID,
+			HostPorts:    namedHostPorts(c.Ports, c.Labels),
+			Health:       healthFromStatus(c.Status),
+			MemoryLimit:  limits.Memory,
+			CPULimit:     float64(limits.NanoCPUs) / 1e9,
+			PidsLimit:    pidsLimit(limits.PidsLimit),
 		})
 	}
 
 	return statuses, nil
 }
 
-func formatPorts(ports []types.Port) string {
-	var parts []string
+// formatPorts renders published ports, naming them from the container's port
+// labels and collapsing consecutive ports of the same name into a range
+func formatPorts(ports []types.Port, labels map[string]string) string {
+	names := portNames(labels)
+
+	// Docker lists IPv4 and IPv6 bindings separately
+	seen := make(map[string]bool)
+	var published []types.Port
 	for _, p := range ports {
-		if p.PublicPort > 0 {
-			parts = append(parts, fmt.Sprintf("%d->%d/%s", p.PublicPort, p.PrivatePort, p.Type))
+		key := portKey(int(p.PublicPort), p.Type)
+		if p.PublicPort > 0 && !seen[key] {
+			seen[key] = true
+			published = append(published, p)
+		}
+	}
+	sort.Slice(published, func(i, j int) bool { return published[i].PublicPort < published[j].PublicPort })
+
+	var parts []string
+	for i := 0; i < len(published); {
+		p := published[i]
+		name := names[portKey(int(p.PrivatePort), p.Type)]
+
+		// Extend the run while ports are consecutive on both sides with the same name
+		j := i + 1
+		for j < len(published) {
+			q := published[j]
+			if q.Type != p.Type || names[portKey(int(q.PrivatePort), q.Type)] != name ||
+				q.PublicPort != p.PublicPort+uint16(j-i) || q.PrivatePort != p.PrivatePort+uint16(j-i) {
+				break
+			}
+			j++
 		}
+
+		part := fmt.Sprintf("%d->%d/%s", p.PublicPort, p.PrivatePort, p.Type)
+		if j-i > 1 {
+			last := published[j-1]
+			part = fmt.Sprintf("%d-%d->%d-%d/%s", p.PublicPort, last.PublicPort, p.PrivatePort, last.PrivatePort, p.Type)
+		}
+		if name != "" {
+			part = name + " " + part
+		}
+		parts = append(parts, part)
+		i = j
 	}
+
 	if len(parts) == 0 {
 		return "-"
 	}
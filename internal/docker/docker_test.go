@@ -0,0 +1,35 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopGrace(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	tests := []struct {
+		name string
+		opts StopOptions
+		want time.Duration
+	}{
+		{"docker default", StopOptions{}, 0},
+		{"timeout", StopOptions{Timeout: 60 * time.Second}, 60 * time.Second},
+		{"timeout rounded", StopOptions{Timeout: 1500 * time.Millisecond}, 2 * time.Second},
+		{"just sent", StopOptions{Timeout: 60 * time.Second, Since: now}, 60 * time.Second},
+		{"remaining", StopOptions{Timeout: 60 * time.Second, Since: ago(20 * time.Second)}, 40 * time.Second},
+		{"remaining rounded up", StopOptions{Timeout: 60 * time.Second, Since: ago(20500 * time.Millisecond)}, 40 * time.Second},
+		{"default timeout counts from since", StopOptions{Since: ago(3 * time.Second)}, 7 * time.Second},
+		{"floored when used up", StopOptions{Timeout: 60 * time.Second, Since: ago(60 * time.Second)}, minStopGrace},
+		{"floored when overrun", StopOptions{Timeout: 60 * time.Second, Since: ago(5 * time.Minute)}, minStopGrace},
+		{"floor capped by timeout", StopOptions{Timeout: 2 * time.Second, Since: ago(time.Minute)}, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stopGrace(tt.opts, now); got != tt.want {
+				t.Errorf("stopGrace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hostathome/cli/internal/registry"
+)
+
+// envLabel holds a hash of the environment a container was created with, so
+// changes can be detected without exposing secret values in labels
+const envLabel = "hostathome.env"
+
+// MissingEnvError reports required environment variables that have no value
+type MissingEnvError struct {
+	Names []string
+}
+
+func (e *MissingEnvError) Error() string {
+	return fmt.Sprintf("required environment variables not set: %s", strings.Join(e.Names, ", "))
+}
+
+// containerEnv resolves a server's environment from the game's defaults and
+// the values set for the server, as sorted KEY=VALUE pairs
+func containerEnv(game *registry.Game, values map[string]string) ([]string, error) {
+	env := make(map[string]string)
+	for _, v := range game.Env {
+		if v.Default != "" {
+			env[v.Name] = v.Default
+		}
+	}
+	for name, value := range values {
+		env[name] = value
+	}
+
+	var missing []string
+	for _, v := range game.Env {
+		if v.Required && env[v.Name] == "" {
+			missing = append(missing, v.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingEnvError{Names: missing}
+	}
+
+	pairs := make([]string, 0, len(env))
+	for name, value := range env {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs, nil
+}
+
+// envHash returns the label value identifying an environment, or "" if it is empty
+func envHash(env []string) string {
+	if len(env) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(env, "\n")))
+	return hex.EncodeToString(sum[:])[:16]
+}
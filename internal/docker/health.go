@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/hostathome/cli/internal/registry"
+)
+
+// healthConfig translates a game's healthcheck into Docker's, or returns nil if it has none
+func healthConfig(game *registry.Game) (*container.HealthConfig, error) {
+	hc := game.Healthcheck
+	if hc == nil {
+		return nil, nil
+	}
+
+	var test []string
+	switch {
+	case hc.Command != "" && hc.Port != "":
+		return nil, fmt.Errorf("healthcheck sets both a command and a port")
+	case hc.Command != "":
+		test = []string{"CMD-SHELL", hc.Command}
+	case hc.Port != "":
+		p := game.Port(hc.Port)
+		if p == nil {
+			return nil, fmt.Errorf("healthcheck port '%s' is not a game port", hc.Port)
+		}
+		if p.Proto() != "tcp" {
+			return nil, fmt.Errorf("healthcheck port '%s' is not a TCP port", hc.Port)
+		}
+		// Images differ in what they ship, so try netcat and fall back to bash's /dev/tcp
+		test = []string{"CMD-SHELL", fmt.Sprintf(
+			"nc -z 127.0.0.1 %d 2>/dev/null || bash -c 'exec 3<>/dev/tcp/127.0.0.1/%d'", p.Internal, p.Internal)}
+	default:
+		return nil, fmt.Errorf("healthcheck needs a command or a port")
+	}
+
+	return &container.HealthConfig{
+		Test:        test,
+		Interval:    time.Duration(hc.Interval) * time.Second,
+		Timeout:     time.Duration(hc.Timeout) * time.Second,
+		Retries:     hc.Retries,
+		StartPeriod: time.Duration(hc.StartPeriod) * time.Second,
+	}, nil
+}
+
+// healthFromStatus extracts the health ("healthy", "unhealthy" or "starting")
+// from a container status such as "Up 5 minutes (healthy)", or "" if the
+// container has no healthcheck
+func healthFromStatus(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(healthy)"):
+		return "healthy"
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.HasSuffix(status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
@@ -0,0 +1,379 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+	"github.com/hostathome/cli/internal/ui"
+)
+
+// portLabelPrefix labels a container with the internal port of each named game port
+const portLabelPrefix = "hostathome.port."
+
+// otherProcess is reported as the owner of host ports not bound by a hostathome container
+const otherProcess = "another process"
+
+// portClaims maps "port/proto" to the owner of a host port
+type portClaims map[string]string
+
+func portKey(port int, proto string) string {
+	return fmt.Sprintf("%d/%s", port, proto)
+}
+
+// owner returns who holds any of count host ports starting at port, or "" if all are free
+func (pc portClaims) owner(port, count int, proto string) string {
+	for p := port; p < port+count; p++ {
+		if owner, ok := pc[portKey(p, proto)]; ok {
+			return owner
+		}
+		if !portFree(p, proto) {
+			return otherProcess
+		}
+	}
+	return ""
+}
+
+// portFree reports whether a host port can currently be bound, replaced in tests
+var portFree = hostPortFree
+
+// hostPortFree reports whether a port can currently be bound on the host
+func hostPortFree(port int, proto string) bool {
+	addr := fmt.Sprintf(":%d", port)
+	if proto == "udp" {
+		conn, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
+
+// hostathomePorts returns the host ports bound by hostathome containers other
+// than exclude, including stopped ones since they claim their ports again when started
+func hostathomePorts(ctx context.Context, cli *client.Client, exclude string) (portClaims, error) {
+	containers, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "hostathome=true")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims := make(portClaims)
+	for _, c := range containers {
+		if len(c.Names) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(c.Names[0], "/")
+		if name == exclude {
+			continue
+		}
+
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		if info.HostConfig == nil {
+			continue
+		}
+		for internal, bindings := range info.HostConfig.PortBindings {
+			for _, b := range bindings {
+				if port, err := strconv.Atoi(b.HostPort); err == nil {
+					claims[portKey(port, internal.Proto())] = "container " + name
+				}
+			}
+		}
+	}
+	return claims, nil
+}
+
+// allocatePorts picks the host ports for a new container and saves them in
+// the server manifest, see assignPorts
+func allocatePorts(ctx context.Context, cli *client.Client, instance string, game *registry.Game, autoPorts bool) ([]registry.Port, error) {
+	manifest, err := server.LoadManifest(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := hostathomePorts(ctx, cli, containerPrefix+instance)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := assignPorts(instance, game, manifest, claims, autoPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.Game = game.Name
+	if err := server.SaveManifest(instance, manifest); err != nil {
+		return nil, fmt.Errorf("failed to save server manifest: %w", err)
+	}
+
+	return ports, nil
+}
+
+// assignPorts picks the host ports of a server instance. Ports pinned in the
+// manifest are used as given, and a conflict is an error unless autoPorts is
+// set. Other ports reuse the port allocated last time while it is free, so
+// runs are stable, and otherwise start from the game's default: a default held
+// by another process is an error unless autoPorts is set, one held by another
+// hostathome instance is moved to the next free port. The allocated ports are
+// recorded in the manifest; the caller saves it.
+func assignPorts(instance string, game *registry.Game, manifest *server.Manifest, claims portClaims, autoPorts bool) ([]registry.Port, error) {
+	ports := make([]registry.Port, 0, len(game.Ports))
+	allocated := make(map[string]int)
+	for _, p := range game.Ports {
+		if p.Internal <= 0 || p.Host <= 0 {
+			// Not published on the host
+			ports = append(ports, p)
+			continue
+		}
+
+		// Earlier versions saved every chosen port as a pin, so a pin on the
+		// default port is taken as not pinned
+		if manifest.Ports[p.Name] == p.Host {
+			delete(manifest.Ports, p.Name)
+		}
+
+		pinned := manifest.Ports[p.Name]
+		if pinned > 0 {
+			if err := ValidatePort(pinned, "saved "+p.Name); err != nil {
+				return nil, err
+			}
+			p.Host = pinned
+		} else if last := manifest.AllocatedPorts[p.Name]; last > 0 && ValidatePort(last, p.Name) == nil &&
+			claims.owner(last, p.Count(), p.Proto()) == "" {
+			p.Host = last
+		}
+
+		owner := claims.owner(p.Host, p.Count(), p.Proto())
+		if owner != "" && !autoPorts && (pinned > 0 || owner == otherProcess) {
+			return nil, fmt.Errorf("%s port %s is already in use by %s (use --auto-ports to pick a free port)", p.Name, p, owner)
+		}
+		if owner != "" {
+			taken := p.String()
+			for owner != "" {
+				if p.Host+p.Count()-1 >= maxPort {
+					return nil, fmt.Errorf("no free %s port available", p.Name)
+				}
+				p.Host++
+				owner = claims.owner(p.Host, p.Count(), p.Proto())
+			}
+			ui.Info("%s port %s is in use, using %s", p.Name, taken, p)
+		}
+
+		for i := 0; i < p.Count(); i++ {
+			claims[portKey(p.Host+i, p.Proto())] = "container " + containerPrefix + instance
+		}
+		if pinned == 0 {
+			allocated[p.Name] = p.Host
+		}
+		ports = append(ports, p)
+	}
+
+	if len(manifest.Ports) == 0 {
+		manifest.Ports = nil
+	}
+	manifest.AllocatedPorts = allocated
+	if len(allocated) == 0 {
+		manifest.AllocatedPorts = nil
+	}
+	return ports, nil
+}
+
+// checkContainerPorts verifies that the host ports an existing container binds are free
+func checkContainerPorts(ctx context.Context, cli *client.Client, containerID, instance string) error {
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	if info.HostConfig == nil {
+		return nil
+	}
+
+	claims, err := hostathomePorts(ctx, cli, containerPrefix+instance)
+	if err != nil {
+		return err
+	}
+
+	for internal, bindings := range info.HostConfig.PortBindings {
+		for _, b := range bindings {
+			port, err := strconv.Atoi(b.HostPort)
+			if err != nil {
+				continue
+			}
+			if owner := claims.owner(port, 1, internal.Proto()); owner != "" {
+				return fmt.Errorf("host port %s is already in use by %s (run 'hostathome remove' and run again with --auto-ports)", portKey(port, internal.Proto()), owner)
+			}
+		}
+	}
+	return nil
+}
+
+// pinnedPortsDiffer reports whether ports saved in a server manifest differ from
+// the ports a container was created with
+func pinnedPortsDiffer(pinned map[string]int, current []registry.Port) bool {
+	for _, p := range current {
+		if port := pinned[p.Name]; port > 0 && port != p.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// publishedPorts returns the game's ports with the host ports an existing container binds
+func publishedPorts(ctx context.Context, cli *client.Client, containerID string, game *registry.Game) ([]registry.Port, error) {
+	info, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make([]registry.Port, 0, len(game.Ports))
+	for _, p := range game.Ports {
+		p.Host = 0
+		if info.HostConfig != nil && p.Internal > 0 {
+			bindings := info.HostConfig.PortBindings[nat.Port(portKey(p.Internal, p.Proto()))]
+			if len(bindings) > 0 {
+				p.Host, _ = strconv.Atoi(bindings[0].HostPort)
+			}
+		}
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// portBindings returns the Docker port configuration for a list of ports,
+// along with container labels naming each port for status output
+func portBindings(ports []registry.Port) (nat.PortSet, nat.PortMap, map[string]string) {
+	exposed := nat.PortSet{}
+	bindings := nat.PortMap{}
+	labels := make(map[string]string)
+
+	for _, p := range ports {
+		if p.Internal <= 0 || p.Host <= 0 {
+			continue
+		}
+		for i := 0; i < p.Count(); i++ {
+			internal := nat.Port(portKey(p.Internal+i, p.Proto()))
+			bindings[internal] = []nat.PortBinding{{HostPort: strconv.Itoa(p.Host + i)}}
+			exposed[internal] = struct{}{}
+		}
+		labels[portLabelPrefix+p.Name] = fmt.Sprintf("%d/%s/%d", p.Internal, p.Proto(), p.Count())
+	}
+	return exposed, bindings, labels
+}
+
+// portNames maps "port/proto" container ports to game port names using container labels
+func portNames(labels map[string]string) map[string]string {
+	names := make(map[string]string)
+	for key, value := range labels {
+		name, ok := strings.CutPrefix(key, portLabelPrefix)
+		if !ok {
+			continue
+		}
+		var internal, count int
+		var proto string
+		if _, err := fmt.Sscanf(strings.ReplaceAll(value, "/", " "), "%d %s %d", &internal, &proto, &count); err != nil {
+			continue
+		}
+		for i := 0; i < count; i++ {
+			names[portKey(internal+i, proto)] = name
+		}
+	}
+	return names
+}
+
+// namedHostPorts maps game port names to the lowest host port they are published on
+func namedHostPorts(ports []types.Port, labels map[string]string) map[string]int {
+	names := portNames(labels)
+	hostPorts := make(map[string]int)
+	for _, p := range ports {
+		name := names[portKey(int(p.PrivatePort), p.Type)]
+		if name == "" || p.PublicPort == 0 {
+			continue
+		}
+		if current, ok := hostPorts[name]; !ok || int(p.PublicPort) < current {
+			hostPorts[name] = int(p.PublicPort)
+		}
+	}
+	return hostPorts
+}
+
+// portBindingList lists the distinct published ports of a container, sorted by host port
+func portBindingList(ports []types.Port, labels map[string]string) []PortBinding {
+	names := portNames(labels)
+	seen := make(map[string]bool)
+	bindings := []PortBinding{}
+	for _, p := range ports {
+		key := portKey(int(p.PublicPort), p.Type)
+		// Docker lists IPv4 and IPv6 bindings separately
+		if p.PublicPort == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		bindings = append(bindings, PortBinding{
+			Name:      names[portKey(int(p.PrivatePort), p.Type)],
+			Host:      int(p.PublicPort),
+			Container: int(p.PrivatePort),
+			Protocol:  p.Type,
+		})
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].Host < bindings[j].Host })
+	return bindings
+}
+
+// PublishedPort returns the host port a server instance's container binds for
+// a named game port, or 0 if the port isn't published
+func PublishedPort(instance string, game *registry.Game, name string) (int, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return 0, fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return 0, err
+	}
+
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil {
+		return 0, err
+	}
+	if c == nil {
+		return 0, fmt.Errorf("container %s not found", containerPrefix+instance)
+	}
+
+	ports, err := publishedPorts(ctx, cli, c.ID, game)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range ports {
+		if p.Name == name {
+			return p.Host, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+)
+
+func TestAssignPorts(t *testing.T) {
+	game := &registry.Game{
+		Name: "minecraft",
+		Ports: registry.PortList{
+			{Name: "player", Internal: 25565, Host: 25565},
+			{Name: "query", Protocol: "udp", Internal: 27015, Host: 27015, Range: 2},
+			{Name: "internal", Internal: 8080},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		pinned    map[string]int
+		allocated map[string]int
+		// inUse are "port/proto" keys held by another process
+		inUse []string
+		// claims are "port/proto" keys held by another hostathome container
+		claims        []string
+		autoPorts     bool
+		want          map[string]int
+		wantPinned    map[string]int
+		wantAllocated map[string]int
+		wantErr       string
+	}{
+		{
+			name:          "defaults",
+			want:          map[string]int{"player": 25565, "query": 27015},
+			wantAllocated: map[string]int{"player": 25565, "query": 27015},
+		},
+		{
+			name:          "other instance moves default",
+			claims:        []string{"25565/tcp", "27016/udp"},
+			want:          map[string]int{"player": 25566, "query": 27017},
+			wantAllocated: map[string]int{"player": 25566, "query": 27017},
+		},
+		{
+			name:    "other process holds default",
+			inUse:   []string{"25565/tcp"},
+			wantErr: "player port 25565/tcp is already in use by another process",
+		},
+		{
+			name:          "other process with auto ports",
+			inUse:         []string{"25565/tcp", "25566/tcp"},
+			autoPorts:     true,
+			want:          map[string]int{"player": 25567, "query": 27015},
+			wantAllocated: map[string]int{"player": 25567, "query": 27015},
+		},
+		{
+			name:          "pinned",
+			pinned:        map[string]int{"player": 30000},
+			want:          map[string]int{"player": 30000, "query": 27015},
+			wantPinned:    map[string]int{"player": 30000},
+			wantAllocated: map[string]int{"query": 27015},
+		},
+		{
+			name:    "pinned in use by other instance",
+			pinned:  map[string]int{"player": 30000},
+			claims:  []string{"30000/tcp"},
+			wantErr: "player port 30000/tcp is already in use by container hostathome-other",
+		},
+		{
+			name:          "pinned in use with auto ports",
+			pinned:        map[string]int{"player": 30000},
+			claims:        []string{"30000/tcp"},
+			autoPorts:     true,
+			want:          map[string]int{"player": 30001, "query": 27015},
+			wantPinned:    map[string]int{"player": 30000},
+			wantAllocated: map[string]int{"query": 27015},
+		},
+		{
+			name:          "allocated reused",
+			allocated:     map[string]int{"player": 25570, "query": 27015},
+			want:          map[string]int{"player": 25570, "query": 27015},
+			wantAllocated: map[string]int{"player": 25570, "query": 27015},
+		},
+		{
+			name:          "allocated taken falls back to default",
+			allocated:     map[string]int{"player": 25570},
+			claims:        []string{"25570/tcp"},
+			want:          map[string]int{"player": 25565, "query": 27015},
+			wantAllocated: map[string]int{"player": 25565, "query": 27015},
+		},
+		{
+			name:          "legacy pin on default is dropped",
+			pinned:        map[string]int{"player": 25565},
+			claims:        []string{"25565/tcp"},
+			want:          map[string]int{"player": 25566, "query": 27015},
+			wantAllocated: map[string]int{"player": 25566, "query": 27015},
+		},
+		{
+			name:    "invalid pin",
+			pinned:  map[string]int{"player": 70000},
+			wantErr: "saved player",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inUse := make(map[string]bool)
+			for _, key := range tt.inUse {
+				inUse[key] = true
+			}
+			portFree = func(port int, proto string) bool { return !inUse[portKey(port, proto)] }
+			t.Cleanup(func() { portFree = hostPortFree })
+
+			claims := make(portClaims)
+			for _, key := range tt.claims {
+				claims[key] = "container hostathome-other"
+			}
+			manifest := &server.Manifest{Ports: maps.Clone(tt.pinned), AllocatedPorts: maps.Clone(tt.allocated)}
+
+			ports, err := assignPorts("minecraft", game, manifest, claims, tt.autoPorts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]int)
+			for _, p := range ports {
+				if p.Host > 0 {
+					got[p.Name] = p.Host
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ports = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(manifest.Ports, tt.wantPinned) {
+				t.Errorf("pinned = %v, want %v", manifest.Ports, tt.wantPinned)
+			}
+			if !reflect.DeepEqual(manifest.AllocatedPorts, tt.wantAllocated) {
+				t.Errorf("allocated = %v, want %v", manifest.AllocatedPorts, tt.wantAllocated)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// readyPollInterval is how often container state and probes are checked while waiting
+const readyPollInterval = time.Second
+
+// startupLogLines is how many log lines are kept to explain a failed start
+const startupLogLines = 20
+
+// ErrNotReady is returned when a server doesn't become ready before the timeout
+var ErrNotReady = errors.New("server did not become ready in time")
+
+// ReadyOptions controls how WaitReady decides a server is ready. A Docker
+// healthcheck takes precedence, then LogPattern, then Probe. With none of them
+// the server is ready as soon as its container runs.
+type ReadyOptions struct {
+	// LogPattern matches the log line the server prints once it accepts players
+	LogPattern *regexp.Regexp
+	// Probe returns nil once the server answers, e.g. a query protocol request
+	Probe func() error
+	// Timeout is how long to wait
+	Timeout time.Duration
+}
+
+// StartupError reports a server that stopped or failed its healthcheck while starting
+type StartupError struct {
+	// Reason explains the failure, e.g. "exited with code 1"
+	Reason string
+	// ExitCode is the container's exit code, or -1 if it is still running
+	ExitCode int
+	// Logs are the last lines the server printed
+	Logs []string
+}
+
+func (e *StartupError) Error() string {
+	return "server " + e.Reason + " during startup"
+}
+
+// WaitReady waits until a server instance's container is ready for players and
+// returns how readiness was determined. It returns a *StartupError if the
+// container stops or becomes unhealthy and ErrNotReady on timeout; use
+// RecentLogs to show what the server printed.
+func WaitReady(instance string, opts ReadyOptions) (string, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return "", fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	cli, err := getClient()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil {
+		return "", err
+	}
+	if c == nil {
+		return "", fmt.Errorf("container %s not found", containerPrefix+instance)
+	}
+
+	info, err := cli.ContainerInspect(ctx, c.ID)
+	if err != nil {
+		return "", err
+	}
+	healthcheck := info.State != nil && info.State.Health != nil
+	tty := info.Config.Tty
+
+	// Watch the logs from the current start for the ready line
+	logMatched := make(chan struct{})
+	method := "container running"
+	switch {
+	case healthcheck:
+		method = "healthcheck"
+	case opts.LogPattern != nil:
+		method = "log line"
+		go watchLogs(ctx, cli, c.ID, info.State.StartedAt, tty, opts.LogPattern, logMatched)
+	case opts.Probe != nil:
+		method = "query response"
+	}
+
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return method, ErrNotReady
+			}
+			return method, err
+		}
+
+		if info.State == nil || !info.State.Running || info.State.Restarting {
+			code := 0
+			if info.State != nil {
+				code = info.State.ExitCode
+			}
+			return method, &StartupError{
+				Reason:   fmt.Sprintf("exited with code %d", code),
+				ExitCode: code,
+				Logs:     recentLogs(cli, c.ID, tty, startupLogLines),
+			}
+		}
+
+		switch {
+		case healthcheck:
+			switch info.State.Health.Status {
+			case "healthy":
+				return method, nil
+			case "unhealthy":
+				return method, &StartupError{
+					Reason:   "failed its healthcheck",
+					ExitCode: -1,
+					Logs:     recentLogs(cli, c.ID, tty, startupLogLines),
+				}
+			}
+		case opts.LogPattern != nil:
+			select {
+			case <-logMatched:
+				return method, nil
+			default:
+			}
+		case opts.Probe != nil:
+			if opts.Probe() == nil {
+				return method, nil
+			}
+		default:
+			return method, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return method, ErrNotReady
+		case <-logMatched:
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchLogs follows a container's logs since it started and closes matched
+// when a line matches pattern
+func watchLogs(ctx context.Context, cli *client.Client, containerID, since string, tty bool, pattern *regexp.Regexp, matched chan<- struct{}) {
+	logs, err := cli.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Since:      since,
+	})
+	if err != nil {
+		return
+	}
+	defer logs.Close()
+
+	r := io.Reader(logs)
+	if !tty {
+		pr, pw := io.Pipe()
+		go func() {
+			_, err := stdcopy.StdCopy(pw, pw, logs)
+			pw.CloseWithError(err)
+		}()
+		defer pr.Close()
+		r = pr
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if pattern.MatchString(scanner.Text()) {
+			close(matched)
+			return
+		}
+	}
+}
+
+// RecentLogs returns the last lines a server instance's container printed
+func RecentLogs(instance string, lines int) ([]string, error) {
+	if err := ValidateGameName(instance); err != nil {
+		return nil, fmt.Errorf("invalid instance name: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := findContainer(ctx, cli, instance, true)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("container %s not found", containerPrefix+instance)
+	}
+
+	info, err := cli.ContainerInspect(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return recentLogs(cli, c.ID, info.Config.Tty, lines), nil
+}
+
+// recentLogs returns the last lines of a container's logs, or nil if they can't be read
+func recentLogs(cli *client.Client, containerID string, tty bool, lines int) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	logs, err := cli.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       strconv.Itoa(lines),
+	})
+	if err != nil {
+		return nil
+	}
+	defer logs.Close()
+
+	var buf strings.Builder
+	if tty {
+		_, err = io.Copy(&buf, logs)
+	} else {
+		_, err = stdcopy.StdCopy(&buf, &buf, logs)
+	}
+	if err != nil && buf.Len() == 0 {
+		return nil
+	}
+
+	text := strings.TrimRight(strings.ReplaceAll(buf.String(), "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package docker
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchResize calls resize whenever the terminal window changes size, until ctx is done
+func watchResize(ctx context.Context, resize func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigs:
+				resize()
+			}
+		}
+	}()
+}
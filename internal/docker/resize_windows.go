@@ -0,0 +1,8 @@
+//go:build windows
+
+package docker
+
+import "context"
+
+// watchResize is a no-op on Windows, which has no SIGWINCH; the console is sized once on attach
+func watchResize(ctx context.Context, resize func()) {}
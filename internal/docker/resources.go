@@ -0,0 +1,131 @@
+package docker
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+	"github.com/hostathome/cli/internal/registry"
+	"github.com/hostathome/cli/internal/server"
+)
+
+const (
+	// minMemory is the smallest memory limit Docker accepts
+	minMemory = 6 * 1024 * 1024
+	// defaultCPUShares is the CPU weight of containers without one
+	defaultCPUShares = 1024
+)
+
+// ValidateResources checks that resource limits can be applied to a container
+func ValidateResources(r registry.Resources) error {
+	_, err := resourceLimits(r)
+	return err
+}
+
+// containerResources resolves a server's limits from the game's recommendations
+// and the overrides in its manifest
+func containerResources(game *registry.Game, manifest *server.Manifest) (container.Resources, error) {
+	var limits registry.Resources
+	if game.Resources != nil {
+		limits = *game.Resources
+	}
+	return resourceLimits(limits.Merge(manifest.Resources))
+}
+
+// resourceLimits translates resource limits into Docker's
+func resourceLimits(r registry.Resources) (container.Resources, error) {
+	var res container.Resources
+
+	if r.Memory != "" {
+		memory, err := units.RAMInBytes(r.Memory)
+		if err != nil {
+			return res, fmt.Errorf("invalid memory limit '%s': %w", r.Memory, err)
+		}
+		if memory != 0 && memory < minMemory {
+			return res, fmt.Errorf("memory limit '%s' is below Docker's minimum of 6m", r.Memory)
+		}
+		res.Memory = memory
+	}
+
+	switch r.Swap {
+	case "":
+	case "-1":
+		res.MemorySwap = -1
+	default:
+		swap, err := units.RAMInBytes(r.Swap)
+		if err != nil {
+			return res, fmt.Errorf("invalid swap limit '%s': %w", r.Swap, err)
+		}
+		if res.Memory == 0 {
+			return res, fmt.Errorf("a swap limit needs a memory limit")
+		}
+		// Docker's limit covers memory and swap together
+		res.MemorySwap = res.Memory + swap
+	}
+
+	if r.CPUs != nil {
+		if *r.CPUs < 0 {
+			return res, fmt.Errorf("invalid CPU limit %g", *r.CPUs)
+		}
+		res.NanoCPUs = int64(*r.CPUs * 1e9)
+	}
+
+	if r.CPUShares != nil {
+		if *r.CPUShares < 0 || *r.CPUShares == 1 {
+			return res, fmt.Errorf("invalid CPU shares %d (minimum 2)", *r.CPUShares)
+		}
+		res.CPUShares = *r.CPUShares
+	}
+
+	if r.PIDs != nil {
+		if *r.PIDs < 0 {
+			return res, fmt.Errorf("invalid PIDs limit %d", *r.PIDs)
+		}
+		if *r.PIDs > 0 {
+			pids := *r.PIDs
+			res.PidsLimit = &pids
+		}
+	}
+
+	return res, nil
+}
+
+// resourcesDiffer reports whether a container's limits differ from the wanted ones.
+// Docker derives a swap limit when none is set, so swap is only compared if wanted.
+func resourcesDiffer(current, wanted container.Resources) bool {
+	if current.Memory != wanted.Memory || current.NanoCPUs != wanted.NanoCPUs {
+		return true
+	}
+	if wanted.MemorySwap != 0 && current.MemorySwap != wanted.MemorySwap {
+		return true
+	}
+	if cpuShares(current.CPUShares) != cpuShares(wanted.CPUShares) {
+		return true
+	}
+	return pidsLimit(current.PidsLimit) != pidsLimit(wanted.PidsLimit)
+}
+
+// limitsRemoved reports whether wanted lifts a limit that current has. Docker
+// takes zero as "unchanged" when updating a container, so that needs a new one.
+func limitsRemoved(current, wanted container.Resources) bool {
+	return (current.Memory > 0 && wanted.Memory == 0) ||
+		(current.NanoCPUs > 0 && wanted.NanoCPUs == 0) ||
+		(cpuShares(current.CPUShares) != defaultCPUShares && wanted.CPUShares == 0) ||
+		(pidsLimit(current.PidsLimit) > 0 && pidsLimit(wanted.PidsLimit) == 0)
+}
+
+// cpuShares returns a CPU weight, treating unset as Docker's default
+func cpuShares(shares int64) int64 {
+	if shares == 0 {
+		return defaultCPUShares
+	}
+	return shares
+}
+
+// pidsLimit returns a PIDs limit, treating unset and unlimited alike
+func pidsLimit(limit *int64) int64 {
+	if limit == nil || *limit < 0 {
+		return 0
+	}
+	return *limit
+}
@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/hostathome/cli/internal/registry"
+)
+
+func TestResourceLimits(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+	int64p := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name    string
+		in      registry.Resources
+		want    container.Resources
+		wantErr bool
+	}{
+		{"none", registry.Resources{}, container.Resources{}, false},
+		{
+			"all",
+			registry.Resources{Memory: "1g", Swap: "512m", CPUs: float(1.5), CPUShares: int64p(512), PIDs: int64p(100)},
+			container.Resources{Memory: 1 << 30, MemorySwap: 1<<30 + 512<<20, NanoCPUs: 1.5e9, CPUShares: 512, PidsLimit: int64p(100)},
+			false,
+		},
+		{"zero removes", registry.Resources{Memory: "0", CPUs: float(0), CPUShares: int64p(0), PIDs: int64p(0)}, container.Resources{}, false},
+		{"unlimited swap", registry.Resources{Memory: "1g", Swap: "-1"}, container.Resources{Memory: 1 << 30, MemorySwap: -1}, false},
+		{"swap without memory", registry.Resources{Swap: "1g"}, container.Resources{}, true},
+		{"memory too small", registry.Resources{Memory: "1m"}, container.Resources{}, true},
+		{"bad memory", registry.Resources{Memory: "lots"}, container.Resources{}, true},
+		{"negative CPUs", registry.Resources{CPUs: float(-1)}, container.Resources{}, true},
+		{"one CPU share", registry.Resources{CPUShares: int64p(1)}, container.Resources{}, true},
+		{"negative PIDs", registry.Resources{PIDs: int64p(-1)}, container.Resources{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resourceLimits(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resourceLimits() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Memory != tt.want.Memory || got.MemorySwap != tt.want.MemorySwap || got.NanoCPUs != tt.want.NanoCPUs ||
+				got.CPUShares != tt.want.CPUShares || pidsLimit(got.PidsLimit) != pidsLimit(tt.want.PidsLimit) {
+				t.Errorf("resourceLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitsRemoved(t *testing.T) {
+	pids := func(v int64) *int64 { return &v }
+	limited := container.Resources{Memory: 1 << 30, NanoCPUs: 2e9, CPUShares: 512, PidsLimit: pids(100)}
+
+	tests := []struct {
+		name          string
+		current       container.Resources
+		wanted        container.Resources
+		changed, lift bool
+	}{
+		{"unchanged", limited, limited, false, false},
+		{"raised", limited, container.Resources{Memory: 2 << 30, NanoCPUs: 4e9, CPUShares: 1024, PidsLimit: pids(200)}, true, false},
+		{"added", container.Resources{}, limited, true, false},
+		{"memory removed", limited, container.Resources{NanoCPUs: 2e9, CPUShares: 512, PidsLimit: pids(100)}, true, true},
+		{"CPUs removed", limited, container.Resources{Memory: 1 << 30, CPUShares: 512, PidsLimit: pids(100)}, true, true},
+		{"CPU shares reset", limited, container.Resources{Memory: 1 << 30, NanoCPUs: 2e9, PidsLimit: pids(100)}, true, true},
+		{"PIDs removed", limited, container.Resources{Memory: 1 << 30, NanoCPUs: 2e9, CPUShares: 512}, true, true},
+		{"PIDs unlimited alike", container.Resources{PidsLimit: pids(-1)}, container.Resources{}, false, false},
+		{"default shares alike", container.Resources{CPUShares: defaultCPUShares}, container.Resources{}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resourcesDiffer(tt.current, tt.wanted); got != tt.changed {
+				t.Errorf("resourcesDiffer() = %v, want %v", got, tt.changed)
+			}
+			if got := limitsRemoved(tt.current, tt.wanted); got != tt.lift {
+				t.Errorf("limitsRemoved() = %v, want %v", got, tt.lift)
+			}
+		})
+	}
+}
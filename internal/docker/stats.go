@@ -0,0 +1,329 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// ContainerStats is a resource usage sample of a server instance's container
+type ContainerStats struct {
+	Game     string `json:"game" yaml:"game"`
+	Instance string `json:"instance" yaml:"instance"`
+	// CPUPercent is relative to one CPU, so a server using two full cores shows 200%
+	CPUPercent  float64 `json:"cpu_percent" yaml:"cpu_percent"`
+	MemoryUsage uint64  `json:"memory_usage" yaml:"memory_usage"`
+	MemoryLimit uint64  `json:"memory_limit" yaml:"memory_limit"`
+	NetRx       uint64  `json:"net_rx" yaml:"net_rx"`
+	NetTx       uint64  `json:"net_tx" yaml:"net_tx"`
+	BlockRead   uint64  `json:"block_read" yaml:"block_read"`
+	BlockWrite  uint64  `json:"block_write" yaml:"block_write"`
+	PIDs        uint64  `json:"pids" yaml:"pids"`
+}
+
+// GetStats samples the resource usage of every running instance of a game, or
+// of every running hostathome container if gameName is empty
+func GetStats(gameName string) ([]ContainerStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerOpTimeout*time.Second)
+	defer cancel()
+
+	cli, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := cli.ContainerList(ctx, container.ListOptions{Filters: statsFilter(gameName)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Each sample takes the daemon about a second, so query containers in parallel
+	stats := make([]ContainerStats, len(containers))
+	errs := make([]error, len(containers))
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		game, instance := containerInstance(c)
+
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			s, err := sampleStats(ctx, cli, id)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", instance, err)
+				return
+			}
+			s.Game, s.Instance = game, instance
+			stats[i] = *s
+		}(i, c.ID)
+	}
+	wg.Wait()
+
+	// Containers that stopped while sampling are left out
+	var result []ContainerStats
+	for i, s := range stats {
+		if errs[i] == nil {
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 && len(containers) > 0 {
+		return nil, errs[0]
+	}
+	sortStats(result)
+	return result, nil
+}
+
+// StatsStream follows the resource usage of running server containers through
+// one Docker stats stream per container
+type StatsStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	cli    *client.Client
+	filter filters.Args
+
+	mu sync.Mutex
+	// streams are the open streams by container ID
+	streams map[string]*containerStream
+}
+
+// containerStream is the stats stream of one container
+type containerStream struct {
+	game, instance string
+	// ready is closed once the first sample arrived or the stream ended
+	ready  chan struct{}
+	latest *ContainerStats
+	done   bool
+}
+
+// StreamStats starts streaming the resource usage of every running instance of
+// a game, or of every running hostathome container if gameName is empty. It
+// returns once each container has sent its first sample. Close the stream when done.
+func StreamStats(gameName string) (*StatsStream, error) {
+	cli, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &StatsStream{
+		ctx:     ctx,
+		cancel:  cancel,
+		cli:     cli,
+		filter:  statsFilter(gameName),
+		streams: make(map[string]*containerStream),
+	}
+	if err := s.refresh(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	timeout := time.After(dockerOpTimeout * time.Second)
+	s.mu.Lock()
+	streams := make([]*containerStream, 0, len(s.streams))
+	for _, cs := range s.streams {
+		streams = append(streams, cs)
+	}
+	s.mu.Unlock()
+	for _, cs := range streams {
+		select {
+		case <-cs.ready:
+		case <-timeout:
+			return s, nil
+		}
+	}
+	return s, nil
+}
+
+// Stats returns the latest sample of each running container. The container
+// list is checked on every call, so servers started later are picked up and
+// stopped ones dropped.
+func (s *StatsStream) Stats() ([]ContainerStats, error) {
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []ContainerStats
+	for _, cs := range s.streams {
+		if cs.latest != nil {
+			result = append(result, *cs.latest)
+		}
+	}
+	sortStats(result)
+	return result, nil
+}
+
+// Close stops all streams
+func (s *StatsStream) Close() {
+	s.cancel()
+}
+
+// refresh opens streams for new containers and closes those of stopped ones
+func (s *StatsStream) refresh() error {
+	ctx, cancel := context.WithTimeout(s.ctx, dockerOpTimeout*time.Second)
+	defer cancel()
+
+	containers, err := s.cli.ContainerList(ctx, container.ListOptions{Filters: s.filter})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	running := make(map[string]bool)
+	for _, c := range containers {
+		running[c.ID] = true
+		// Ended streams are reopened while the container still runs
+		if cs, ok := s.streams[c.ID]; ok && !cs.done {
+			continue
+		}
+		game, instance := containerInstance(c)
+		cs := &containerStream{game: game, instance: instance, ready: make(chan struct{})}
+		s.streams[c.ID] = cs
+		go s.follow(c.ID, cs)
+	}
+	for id, cs := range s.streams {
+		if !running[id] {
+			cs.done = true
+			delete(s.streams, id)
+		}
+	}
+	return nil
+}
+
+// follow decodes a container's stats stream until it ends, keeping the latest sample
+func (s *StatsStream) follow(containerID string, cs *containerStream) {
+	var once sync.Once
+	markReady := func() { once.Do(func() { close(cs.ready) }) }
+	defer func() {
+		s.mu.Lock()
+		cs.done = true
+		s.mu.Unlock()
+		markReady()
+	}()
+
+	resp, err := s.cli.ContainerStats(s.ctx, containerID, true)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var raw container.StatsResponse
+		if err := dec.Decode(&raw); err != nil {
+			return
+		}
+		sample := newContainerStats(raw)
+		sample.Game, sample.Instance = cs.game, cs.instance
+
+		s.mu.Lock()
+		cs.latest = sample
+		s.mu.Unlock()
+		markReady()
+	}
+}
+
+// statsFilter selects the running containers of a game, or all hostathome containers
+func statsFilter(gameName string) filters.Args {
+	filterArgs := filters.NewArgs(filters.Arg("label", "hostathome=true"))
+	if gameName != "" {
+		filterArgs.Add("label", "hostathome.game="+gameName)
+	}
+	return filterArgs
+}
+
+// containerInstance returns the game and instance a container runs
+func containerInstance(c types.Container) (game, instance string) {
+	instance = c.Labels["hostathome.instance"]
+	if instance == "" && len(c.Names) > 0 {
+		instance = strings.TrimPrefix(c.Names[0], "/"+containerPrefix)
+	}
+	game = c.Labels["hostathome.game"]
+	if game == "" {
+		game = instance
+	}
+	return game, instance
+}
+
+// sortStats orders samples by instance name
+func sortStats(stats []ContainerStats) {
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Instance < stats[j].Instance })
+}
+
+// sampleStats reads a single stats sample, which includes the previous CPU reading
+func sampleStats(ctx context.Context, cli *client.Client, containerID string) (*ContainerStats, error) {
+	resp, err := cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var raw container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid stats: %w", err)
+	}
+	return newContainerStats(raw), nil
+}
+
+// newContainerStats summarizes a raw stats sample
+func newContainerStats(raw container.StatsResponse) *ContainerStats {
+	s := &ContainerStats{
+		CPUPercent:  cpuPercent(raw),
+		MemoryUsage: memoryUsage(raw.MemoryStats),
+		MemoryLimit: raw.MemoryStats.Limit,
+		PIDs:        raw.PidsStats.Current,
+	}
+	for _, n := range raw.Networks {
+		s.NetRx += n.RxBytes
+		s.NetTx += n.TxBytes
+	}
+	for _, e := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			s.BlockRead += e.Value
+		case "write":
+			s.BlockWrite += e.Value
+		}
+	}
+	return s
+}
+
+// cpuPercent computes CPU usage between the previous and current sample, as "docker stats" does
+func cpuPercent(s container.StatsResponse) float64 {
+	// The first sample of a stream has no previous reading to compare against
+	if s.PreCPUStats.SystemUsage == 0 {
+		return 0
+	}
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	cpus := float64(s.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// memoryUsage returns memory in use without the page cache, as "docker stats" does
+func memoryUsage(m container.MemoryStats) uint64 {
+	// cgroup v1 reports the cache as total_inactive_file, cgroup v2 as inactive_file
+	for _, key := range []string{"total_inactive_file", "inactive_file"} {
+		if cache, ok := m.Stats[key]; ok && cache < m.Usage {
+			return m.Usage - cache
+		}
+	}
+	return m.Usage
+}
@@ -0,0 +1,166 @@
+// Package minisign verifies signatures made with minisign (https://jedisct1.github.io/minisign/)
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// algLegacy signs the message itself
+	algLegacy = "Ed"
+	// algHashed signs the BLAKE2b-512 hash of the message (the minisign default)
+	algHashed = "ED"
+
+	trustedCommentPrefix = "trusted comment: "
+)
+
+var (
+	// ErrUnknownKey is returned when a signature was made with a key that isn't trusted
+	ErrUnknownKey = errors.New("signed with an untrusted key")
+	// ErrInvalidSignature is returned when a signature doesn't match the data
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// PublicKey is a minisign public key
+type PublicKey struct {
+	ID  [8]byte
+	Key ed25519.PublicKey
+}
+
+// String returns the key ID in the hex form minisign prints
+func (k PublicKey) String() string {
+	id := k.ID
+	// minisign shows the little-endian ID as a number
+	for i, j := 0, len(id)-1; i < j; i, j = i+1, j-1 {
+		id[i], id[j] = id[j], id[i]
+	}
+	return strings.ToUpper(hex.EncodeToString(id[:]))
+}
+
+// Signature is a parsed .minisig file
+type Signature struct {
+	Algorithm      string
+	KeyID          [8]byte
+	Signature      []byte
+	TrustedComment string
+	GlobalSig      []byte
+}
+
+// ParsePublicKey parses a public key file, or the base64 key on its own
+func ParsePublicKey(data []byte) (PublicKey, error) {
+	var key PublicKey
+	line := lastLine(data)
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize {
+		return key, fmt.Errorf("invalid minisign public key")
+	}
+	if string(raw[:2]) != algLegacy {
+		return key, fmt.Errorf("unsupported key algorithm %q", raw[:2])
+	}
+	copy(key.ID[:], raw[2:10])
+	key.Key = ed25519.PublicKey(raw[10:])
+	return key, nil
+}
+
+// ParseSignature parses the contents of a .minisig file
+func ParseSignature(data []byte) (*Signature, error) {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "untrusted comment:") || !strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		return nil, fmt.Errorf("invalid minisign signature file")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign global signature")
+	}
+
+	sig := &Signature{
+		Algorithm:      string(raw[:2]),
+		Signature:      raw[10:],
+		TrustedComment: strings.TrimPrefix(lines[2], trustedCommentPrefix),
+		GlobalSig:      global,
+	}
+	copy(sig.KeyID[:], raw[2:10])
+	if sig.Algorithm != algLegacy && sig.Algorithm != algHashed {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+	return sig, nil
+}
+
+// Verify checks that sig is a signature of data by one of keys, including the
+// signature of its trusted comment
+func Verify(keys []PublicKey, data []byte, sig *Signature) error {
+	for _, key := range keys {
+		if key.ID != sig.KeyID {
+			continue
+		}
+
+		message := data
+		if sig.Algorithm == algHashed {
+			sum := blake2b.Sum512(data)
+			message = sum[:]
+		}
+		if !ed25519.Verify(key.Key, message, sig.Signature) {
+			return ErrInvalidSignature
+		}
+
+		global := append(bytes.Clone(sig.Signature), sig.TrustedComment...)
+		if !ed25519.Verify(key.Key, global, sig.GlobalSig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return ErrUnknownKey
+}
+
+// LoadKeys reads every *.pub file in dir. A missing directory yields no keys.
+func LoadKeys(dir string) ([]PublicKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []PublicKey
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// lastLine returns the last non-empty line that isn't a comment
+func lastLine(data []byte) string {
+	var last string
+	for _, l := range strings.Split(string(data), "\n") {
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "untrusted comment:") {
+			last = l
+		}
+	}
+	return last
+}
@@ -0,0 +1,226 @@
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// testKey is a key pair with its minisign key ID
+type testKey struct {
+	id   [8]byte
+	priv ed25519.PrivateKey
+}
+
+func newTestKey(seed byte, id ...byte) testKey {
+	k := testKey{priv: ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))}
+	copy(k.id[:], id)
+	return k
+}
+
+// publicKeyFile returns the key in minisign's .pub format
+func (k testKey) publicKeyFile() []byte {
+	raw := append([]byte(algLegacy), k.id[:]...)
+	raw = append(raw, k.priv.Public().(ed25519.PublicKey)...)
+	return []byte("untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(raw) + "\n")
+}
+
+func (k testKey) publicKey(t *testing.T) PublicKey {
+	t.Helper()
+	key, err := ParsePublicKey(k.publicKeyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// sign returns a .minisig file for data made with algorithm alg
+func (k testKey) sign(alg string, data []byte, trustedComment string) []byte {
+	message := data
+	if alg == algHashed {
+		sum := blake2b.Sum512(data)
+		message = sum[:]
+	}
+	sig := ed25519.Sign(k.priv, message)
+	global := ed25519.Sign(k.priv, append(append([]byte(nil), sig...), trustedComment...))
+
+	raw := append([]byte(alg), k.id[:]...)
+	raw = append(raw, sig...)
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(raw) + "\n" +
+		trustedCommentPrefix + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func TestVerify(t *testing.T) {
+	key := newTestKey(1, 1, 2, 3, 4, 5, 6, 7, 8)
+	other := newTestKey(2, 9, 9, 9, 9, 9, 9, 9, 9)
+	// impostor claims key's ID with a different key
+	impostor := newTestKey(3, 1, 2, 3, 4, 5, 6, 7, 8)
+	data := []byte("name: minecraft\nimage: ghcr.io/hostathome/minecraft-server\n")
+	const comment = "timestamp:1736000000\tfile:minecraft.yaml"
+
+	tests := []struct {
+		name    string
+		keys    []PublicKey
+		sig     []byte
+		data    []byte
+		edit    func(*Signature)
+		wantErr error
+	}{
+		{"legacy", []PublicKey{key.publicKey(t)}, key.sign(algLegacy, data, comment), data, nil, nil},
+		{"hashed", []PublicKey{key.publicKey(t)}, key.sign(algHashed, data, comment), data, nil, nil},
+		{"second trusted key", []PublicKey{other.publicKey(t), key.publicKey(t)}, key.sign(algHashed, data, comment), data, nil, nil},
+		{"tampered data", []PublicKey{key.publicKey(t)}, key.sign(algHashed, data, comment), append(data, '#'), nil, ErrInvalidSignature},
+		{"tampered legacy data", []PublicKey{key.publicKey(t)}, key.sign(algLegacy, data, comment), data[1:], nil, ErrInvalidSignature},
+		{
+			"tampered trusted comment",
+			[]PublicKey{key.publicKey(t)}, key.sign(algHashed, data, comment), data,
+			func(s *Signature) { s.TrustedComment = "timestamp:1736000000\tfile:other.yaml" },
+			ErrInvalidSignature,
+		},
+		{
+			"algorithm swapped",
+			[]PublicKey{key.publicKey(t)}, key.sign(algHashed, data, comment), data,
+			func(s *Signature) { s.Algorithm = algLegacy },
+			ErrInvalidSignature,
+		},
+		{"unknown key", []PublicKey{other.publicKey(t)}, key.sign(algHashed, data, comment), data, nil, ErrUnknownKey},
+		{"no keys", nil, key.sign(algHashed, data, comment), data, nil, ErrUnknownKey},
+		{"same key ID, different key", []PublicKey{key.publicKey(t)}, impostor.sign(algHashed, data, comment), data, nil, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := ParseSignature(tt.sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig.TrustedComment != comment {
+				t.Errorf("TrustedComment = %q, want %q", sig.TrustedComment, comment)
+			}
+			if tt.edit != nil {
+				tt.edit(sig)
+			}
+			if err := Verify(tt.keys, tt.data, sig); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSignatureInvalid(t *testing.T) {
+	key := newTestKey(1, 1, 2, 3, 4, 5, 6, 7, 8)
+	valid := strings.Split(strings.TrimSpace(string(key.sign(algHashed, []byte("data"), "comment"))), "\n")
+	sigLine := func(alg string, n int) string {
+		raw := append([]byte(alg), make([]byte, n)...)
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{"empty", nil},
+		{"missing global signature", valid[:3]},
+		{"extra line", append(append([]string{}, valid...), "more")},
+		{"no untrusted comment", []string{"comment", valid[1], valid[2], valid[3]}},
+		{"no trusted comment", []string{valid[0], valid[1], "comment", valid[3]}},
+		{"bad base64", []string{valid[0], "!!!", valid[2], valid[3]}},
+		{"short signature", []string{valid[0], sigLine(algHashed, 8+ed25519.SignatureSize-1), valid[2], valid[3]}},
+		{"short global signature", []string{valid[0], valid[1], valid[2], base64.StdEncoding.EncodeToString(make([]byte, 10))}},
+		{"unsupported algorithm", []string{valid[0], sigLine("Xx", 8+ed25519.SignatureSize), valid[2], valid[3]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSignature([]byte(strings.Join(tt.lines, "\n"))); err == nil {
+				t.Error("ParseSignature() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestParseSignatureLineEndings(t *testing.T) {
+	key := newTestKey(1, 1, 2, 3, 4, 5, 6, 7, 8)
+	data := []byte("data")
+	file := strings.ReplaceAll(string(key.sign(algHashed, data, "comment")), "\n", "\r\n")
+
+	sig, err := ParseSignature([]byte(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify([]PublicKey{key.publicKey(t)}, data, sig); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key := newTestKey(1, 1, 2, 3, 4, 5, 6, 7, 8)
+	file := key.publicKeyFile()
+	bare := strings.Split(string(file), "\n")[1]
+
+	for name, data := range map[string]string{"file": string(file), "bare": bare, "padded": "\n  " + bare + "  \n\n"} {
+		got, err := ParsePublicKey([]byte(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got.ID != key.id || !got.Key.Equal(key.priv.Public()) {
+			t.Errorf("%s: parsed %v, want key %v", name, got, key.id)
+		}
+	}
+
+	// minisign prints key IDs as little-endian numbers
+	if got := key.publicKey(t).String(); got != "0807060504030201" {
+		t.Errorf("String() = %q, want %q", got, "0807060504030201")
+	}
+
+	raw, _ := base64.StdEncoding.DecodeString(bare)
+	for name, data := range map[string]string{
+		"empty":           "",
+		"bad base64":      "not a key",
+		"short":           base64.StdEncoding.EncodeToString(raw[:len(raw)-1]),
+		"wrong algorithm": base64.StdEncoding.EncodeToString(append([]byte("ED"), raw[2:]...)),
+	} {
+		if _, err := ParsePublicKey([]byte(data)); err == nil {
+			t.Errorf("%s: ParsePublicKey() succeeded, want error", name)
+		}
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	if keys, err := LoadKeys(filepath.Join(t.TempDir(), "missing")); err != nil || keys != nil {
+		t.Errorf("LoadKeys(missing) = %v, %v, want no keys", keys, err)
+	}
+
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"a.pub":     newTestKey(1, 1).publicKeyFile(),
+		"b.pub":     newTestKey(2, 2).publicKeyFile(),
+		"notes.txt": []byte("not a key"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys, err := LoadKeys(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].ID[0] != 1 || keys[1].ID[0] != 2 {
+		t.Errorf("LoadKeys() = %v, want keys 1 and 2", keys)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.pub"), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeys(dir); err == nil || !strings.Contains(err.Error(), "broken.pub") {
+		t.Errorf("LoadKeys() error = %v, want error naming broken.pub", err)
+	}
+}
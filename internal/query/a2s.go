@@ -0,0 +1,236 @@
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	a2sSinglePacket = -1
+	a2sSplitPacket  = -2
+
+	a2sInfoRequest    = 'T'
+	a2sInfoResponse   = 'I'
+	a2sPlayerRequest  = 'U'
+	a2sPlayerResponse = 'D'
+	a2sChallenge      = 'A'
+
+	// a2sMaxPacket is the largest UDP packet a Source server sends
+	a2sMaxPacket = 1400
+	// a2sMaxParts bounds how many parts of a split response are reassembled
+	a2sMaxParts = 32
+)
+
+var a2sInfoPayload = []byte("Source Engine Query\x00")
+
+// queryA2S asks a Source server for its info and player list
+func queryA2S(addr string, timeout time.Duration) (*Result, error) {
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	info, err := a2sRequest(conn, a2sInfoRequest, a2sInfoPayload, a2sInfoResponse)
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+
+	result, err := parseA2SInfo(info)
+	if err != nil {
+		return nil, err
+	}
+	result.Latency = latency
+
+	// Some servers disable the player list, the info is still useful without it
+	if players, err := a2sRequest(conn, a2sPlayerRequest, nil, a2sPlayerResponse); err == nil {
+		result.PlayerNames = parseA2SPlayers(players)
+	}
+
+	return result, nil
+}
+
+// a2sRequest sends a query, answering a challenge if the server sends one, and
+// returns the response body after its header byte
+func a2sRequest(conn net.Conn, request byte, payload []byte, response byte) (*bytes.Reader, error) {
+	challenge := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	// A2S_INFO only needs a challenge on servers that require one
+	if request == a2sInfoRequest {
+		challenge = nil
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		var buf bytes.Buffer
+		_ = binary.Write(&buf, binary.LittleEndian, int32(a2sSinglePacket))
+		buf.WriteByte(request)
+		buf.Write(payload)
+		buf.Write(challenge)
+		if _, err := conn.Write(buf.Bytes()); err != nil {
+			return nil, err
+		}
+
+		data, err := a2sRead(conn)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			return nil, errors.New("empty response")
+		}
+
+		switch data[0] {
+		case response:
+			return bytes.NewReader(data[1:]), nil
+		case a2sChallenge:
+			if len(data) < 5 {
+				return nil, errors.New("invalid challenge")
+			}
+			challenge = data[1:5]
+		default:
+			return nil, fmt.Errorf("unexpected response type 0x%02x", data[0])
+		}
+	}
+	return nil, errors.New("server kept sending challenges")
+}
+
+// a2sRead reads a response, reassembling it if the server split it over several packets
+func a2sRead(conn net.Conn) ([]byte, error) {
+	packet := make([]byte, a2sMaxPacket)
+	n, err := conn.Read(packet)
+	if err != nil {
+		return nil, err
+	}
+	if n < 5 {
+		return nil, errors.New("response too short")
+	}
+
+	header := int32(binary.LittleEndian.Uint32(packet[:4]))
+	if header == a2sSinglePacket {
+		return packet[4:n], nil
+	}
+	if header != a2sSplitPacket {
+		return nil, fmt.Errorf("invalid packet header %d", header)
+	}
+
+	// Source split packets: ID (4), total (1), number (1), size (2), then the payload
+	var parts [][]byte
+	var id int32
+	received := 0
+	for {
+		if n < 12 {
+			return nil, errors.New("split packet too short")
+		}
+		partID := int32(binary.LittleEndian.Uint32(packet[4:8]))
+		if partID < 0 {
+			return nil, errors.New("compressed responses are not supported")
+		}
+		total, number := int(packet[8]), int(packet[9])
+		if total == 0 || total > a2sMaxParts || number >= total {
+			return nil, errors.New("invalid split packet")
+		}
+		if parts == nil {
+			parts = make([][]byte, total)
+			id = partID
+		}
+		if partID == id && number < len(parts) && parts[number] == nil {
+			parts[number] = append([]byte(nil), packet[12:n]...)
+			received++
+		}
+		if received == len(parts) {
+			break
+		}
+
+		if n, err = conn.Read(packet); err != nil {
+			return nil, err
+		}
+		if n < 4 || int32(binary.LittleEndian.Uint32(packet[:4])) != a2sSplitPacket {
+			return nil, errors.New("unexpected packet in split response")
+		}
+	}
+
+	data := bytes.Join(parts, nil)
+	if len(data) < 4 || int32(binary.LittleEndian.Uint32(data[:4])) != a2sSinglePacket {
+		return nil, errors.New("invalid split response")
+	}
+	return data[4:], nil
+}
+
+// parseA2SInfo decodes an A2S_INFO response
+func parseA2SInfo(r *bytes.Reader) (*Result, error) {
+	var protocol byte
+	if err := binary.Read(r, binary.LittleEndian, &protocol); err != nil {
+		return nil, errors.New("truncated info response")
+	}
+	name := readCString(r)
+	_ = readCString(r) // map
+	_ = readCString(r) // folder
+	_ = readCString(r) // game
+	var fields struct {
+		AppID      uint16
+		Players    uint8
+		MaxPlayers uint8
+		Bots       uint8
+		ServerType uint8
+		OS         uint8
+		Visibility uint8
+		VAC        uint8
+	}
+	if err := binary.Read(r, binary.LittleEndian, &fields); err != nil {
+		return nil, errors.New("truncated info response")
+	}
+
+	return &Result{
+		Version:    cleanText(readCString(r)),
+		MOTD:       cleanText(name),
+		Players:    int(fields.Players),
+		MaxPlayers: int(fields.MaxPlayers),
+	}, nil
+}
+
+// parseA2SPlayers decodes the player names from an A2S_PLAYER response
+func parseA2SPlayers(r *bytes.Reader) []string {
+	count, err := r.ReadByte()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < int(count); i++ {
+		if _, err := r.ReadByte(); err != nil { // index
+			break
+		}
+		name := readCString(r)
+		var stats struct {
+			Score    int32
+			Duration float32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &stats); err != nil {
+			break
+		}
+		// Players still connecting have no name yet
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// readCString reads a null-terminated string
+func readCString(r *bytes.Reader) string {
+	var buf bytes.Buffer
+	for {
+		b, err := r.ReadByte()
+		if err != nil || b == 0 {
+			return buf.String()
+		}
+		buf.WriteByte(b)
+	}
+}
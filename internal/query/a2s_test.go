@@ -0,0 +1,233 @@
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a2sInfo builds an A2S_INFO response body after the header byte
+func a2sInfo(name, version string, players, maxPlayers byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(17) // protocol
+	for _, s := range []string{name, "de_dust2", "valheim", "Valheim"} {
+		buf.WriteString(s)
+		buf.WriteByte(0)
+	}
+	binary.Write(&buf, binary.LittleEndian, uint16(0))
+	buf.Write([]byte{players, maxPlayers, 0, 'd', 'l', 0, 1})
+	buf.WriteString(version)
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+// a2sPlayers builds an A2S_PLAYER response body after the header byte
+func a2sPlayers(names ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(len(names)))
+	for i, name := range names {
+		buf.WriteByte(byte(i))
+		buf.WriteString(name)
+		buf.WriteByte(0)
+		binary.Write(&buf, binary.LittleEndian, int32(10*i))
+		binary.Write(&buf, binary.LittleEndian, float32(60))
+	}
+	return buf.Bytes()
+}
+
+func TestParseA2SInfo(t *testing.T) {
+	got, err := parseA2SInfo(bytes.NewReader(a2sInfo("My  §aServer", "0.219.16", 3, 10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Result{Version: "0.219.16", MOTD: "My Server", Players: 3, MaxPlayers: 10}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseA2SInfo() = %+v, want %+v", got, want)
+	}
+
+	for _, n := range []int{0, 1, 30} {
+		if _, err := parseA2SInfo(bytes.NewReader(a2sInfo("x", "1", 0, 0)[:n])); err == nil {
+			t.Errorf("parseA2SInfo accepted a response truncated to %d bytes", n)
+		}
+	}
+}
+
+func TestParseA2SPlayers(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"players", a2sPlayers("alice", "bob"), []string{"alice", "bob"}},
+		{"connecting player skipped", a2sPlayers("alice", "", "carol"), []string{"alice", "carol"}},
+		{"empty", a2sPlayers(), nil},
+		{"no data", nil, nil},
+		{"truncated", a2sPlayers("alice", "bob")[:20], []string{"alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseA2SPlayers(bytes.NewReader(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseA2SPlayers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// single frames a response as a single-packet reply
+func single(kind byte, body []byte) []byte {
+	return append([]byte{0xff, 0xff, 0xff, 0xff, kind}, body...)
+}
+
+// split frames a response as Source split packets of at most size payload bytes
+func split(id int32, response []byte, size int) [][]byte {
+	var chunks [][]byte
+	for len(response) > 0 {
+		n := min(size, len(response))
+		chunks = append(chunks, response[:n])
+		response = response[n:]
+	}
+
+	var packets [][]byte
+	for i, chunk := range chunks {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, int32(a2sSplitPacket))
+		binary.Write(&buf, binary.LittleEndian, id)
+		buf.WriteByte(byte(len(chunks)))
+		buf.WriteByte(byte(i))
+		binary.Write(&buf, binary.LittleEndian, uint16(a2sMaxPacket))
+		buf.Write(chunk)
+		packets = append(packets, buf.Bytes())
+	}
+	return packets
+}
+
+// a2sServer runs a fake Source server. reply returns the packets to send for
+// each request, which is given without its 0xFFFFFFFF header.
+func a2sServer(t *testing.T, reply func(request []byte) [][]byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, a2sMaxPacket)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 5 {
+				continue
+			}
+			for _, p := range reply(append([]byte(nil), buf[4:n]...)) {
+				conn.WriteTo(p, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQueryA2S(t *testing.T) {
+	challenge := []byte{0x01, 0x02, 0x03, 0x04}
+	info := a2sInfo("Valheim Server", "0.219.16", 2, 10)
+	players := a2sPlayers("alice", "bob")
+
+	tests := []struct {
+		name  string
+		reply func(request []byte) [][]byte
+		want  Result
+	}{
+		{
+			"single packets with player challenge",
+			func(request []byte) [][]byte {
+				switch {
+				case request[0] == a2sInfoRequest:
+					return [][]byte{single(a2sInfoResponse, info)}
+				case request[0] == a2sPlayerRequest && bytes.Equal(request[1:], challenge):
+					return [][]byte{single(a2sPlayerResponse, players)}
+				case request[0] == a2sPlayerRequest:
+					return [][]byte{single(a2sChallenge, challenge)}
+				}
+				return nil
+			},
+			Result{Version: "0.219.16", MOTD: "Valheim Server", Players: 2, MaxPlayers: 10, PlayerNames: []string{"alice", "bob"}},
+		},
+		{
+			"info challenge",
+			func(request []byte) [][]byte {
+				if request[0] != a2sInfoRequest {
+					return nil
+				}
+				if !bytes.HasSuffix(request, challenge) {
+					return [][]byte{single(a2sChallenge, challenge)}
+				}
+				return [][]byte{single(a2sInfoResponse, info)}
+			},
+			Result{Version: "0.219.16", MOTD: "Valheim Server", Players: 2, MaxPlayers: 10},
+		},
+		{
+			"split info out of order",
+			func(request []byte) [][]byte {
+				if request[0] != a2sInfoRequest {
+					return nil
+				}
+				packets := split(7, single(a2sInfoResponse, info), 16)
+				// Parts may arrive in any order, and repeated
+				var reply [][]byte
+				for _, i := range []int{2, 0, 0, 3, 1} {
+					reply = append(reply, packets[i])
+				}
+				return reply
+			},
+			Result{Version: "0.219.16", MOTD: "Valheim Server", Players: 2, MaxPlayers: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Servers without a player list don't answer, which takes the full timeout
+			timeout := 2 * time.Second
+			if tt.want.PlayerNames == nil {
+				timeout = 300 * time.Millisecond
+			}
+
+			result, err := Query(A2S, a2sServer(t, tt.reply), timeout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := *result
+			got.Latency = 0
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryA2SInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   [][]byte
+		wantErr string
+	}{
+		{"bad header", [][]byte{{0x01, 0x00, 0x00, 0x00, 'I'}}, "invalid packet header"},
+		{"short", [][]byte{{0xff, 0xff}}, "response too short"},
+		{"unexpected type", [][]byte{single('X', nil)}, "unexpected response type 0x58"},
+		{"compressed", split(-5, single(a2sInfoResponse, []byte("x")), 16), "compressed responses are not supported"},
+		{"too many parts", [][]byte{append(split(1, single(a2sInfoResponse, []byte("x")), 16)[0][:8], 33, 0, 0, 0)}, "invalid split packet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := a2sServer(t, func(request []byte) [][]byte { return tt.reply })
+			_, err := Query(A2S, addr, time.Second)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Query() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
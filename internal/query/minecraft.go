@@ -0,0 +1,199 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// mcProtocolAny asks the server to report its own protocol version
+	mcProtocolAny  = -1
+	mcStateStatus  = 1
+	mcPacketStatus = 0x00
+	mcPacketPing   = 0x01
+	// mcMaxResponse bounds the status JSON, which can include a base64 favicon
+	mcMaxResponse = 1 << 20
+)
+
+// mcStatus is the Server List Ping status response
+type mcStatus struct {
+	Version struct {
+		Name string `json:"name"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+		Sample []struct {
+			Name string `json:"name"`
+		} `json:"sample"`
+	} `json:"players"`
+	Description json.RawMessage `json:"description"`
+}
+
+// queryMinecraft runs a Server List Ping: handshake, status request, then a ping for latency
+func queryMinecraft(addr string, timeout time.Duration) (*Result, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in %s", addr)
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	var handshake bytes.Buffer
+	writeVarInt(&handshake, mcPacketStatus)
+	writeVarInt(&handshake, mcProtocolAny)
+	writeVarInt(&handshake, int32(len(host)))
+	handshake.WriteString(host)
+	_ = binary.Write(&handshake, binary.BigEndian, uint16(port))
+	writeVarInt(&handshake, mcStateStatus)
+	if err := writePacket(conn, handshake.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writePacket(conn, []byte{mcPacketStatus}); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	payload, err := readPacket(r, mcPacketStatus)
+	if err != nil {
+		return nil, err
+	}
+	length, err := readVarInt(payload)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 || int(length) > payload.Len() {
+		return nil, fmt.Errorf("invalid status length %d", length)
+	}
+
+	var status mcStatus
+	if err := json.Unmarshal(payload.Next(int(length)), &status); err != nil {
+		return nil, fmt.Errorf("invalid status response: %w", err)
+	}
+
+	result := &Result{
+		Version:    cleanText(status.Version.Name),
+		MOTD:       cleanText(chatText(status.Description)),
+		Players:    status.Players.Online,
+		MaxPlayers: status.Players.Max,
+	}
+	for _, p := range status.Players.Sample {
+		result.PlayerNames = append(result.PlayerNames, p.Name)
+	}
+
+	// Latency comes from the ping, servers that don't answer it still have a valid status
+	var ping bytes.Buffer
+	writeVarInt(&ping, mcPacketPing)
+	start := time.Now()
+	_ = binary.Write(&ping, binary.BigEndian, start.UnixMilli())
+	if err := writePacket(conn, ping.Bytes()); err == nil {
+		if _, err := readPacket(r, mcPacketPing); err == nil {
+			result.Latency = time.Since(start)
+		}
+	}
+
+	return result, nil
+}
+
+// chatText flattens a description, which is either a string or a chat component
+func chatText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+
+	var component struct {
+		Text  string            `json:"text"`
+		Extra []json.RawMessage `json:"extra"`
+	}
+	if json.Unmarshal(raw, &component) != nil {
+		return ""
+	}
+	text := component.Text
+	for _, extra := range component.Extra {
+		text += chatText(extra)
+	}
+	return text
+}
+
+// writePacket writes a length-prefixed packet
+func writePacket(w io.Writer, data []byte) error {
+	var buf bytes.Buffer
+	writeVarInt(&buf, int32(len(data)))
+	buf.Write(data)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readPacket reads a packet and checks its ID, returning the rest of its payload
+func readPacket(r *bufio.Reader, id int32) (*bytes.Buffer, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if length <= 0 || length > mcMaxResponse {
+		return nil, fmt.Errorf("invalid packet length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	payload := bytes.NewBuffer(data)
+	got, err := readVarInt(payload)
+	if err != nil {
+		return nil, err
+	}
+	if got != id {
+		return nil, fmt.Errorf("unexpected packet 0x%02x", got)
+	}
+	return payload, nil
+}
+
+// writeVarInt writes a protocol VarInt: 7 bits per byte, least significant group first
+func writeVarInt(buf *bytes.Buffer, value int32) {
+	v := uint32(value)
+	for {
+		if v&^0x7F == 0 {
+			buf.WriteByte(byte(v))
+			return
+		}
+		buf.WriteByte(byte(v&0x7F) | 0x80)
+		v >>= 7
+	}
+}
+
+// readVarInt reads a protocol VarInt
+func readVarInt(r io.ByteReader) (int32, error) {
+	var v uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(v), nil
+		}
+	}
+	return 0, errors.New("varint too long")
+}
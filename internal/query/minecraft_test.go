@@ -0,0 +1,186 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVarInt(t *testing.T) {
+	tests := []struct {
+		value   int32
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{255, []byte{0xff, 0x01}},
+		{25565, []byte{0xdd, 0xc7, 0x01}},
+		{2097151, []byte{0xff, 0xff, 0x7f}},
+		{2147483647, []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+		{-1, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+		{-2147483648, []byte{0x80, 0x80, 0x80, 0x80, 0x08}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		writeVarInt(&buf, tt.value)
+		if !bytes.Equal(buf.Bytes(), tt.encoded) {
+			t.Errorf("writeVarInt(%d) = % x, want % x", tt.value, buf.Bytes(), tt.encoded)
+		}
+		got, err := readVarInt(bytes.NewReader(tt.encoded))
+		if err != nil || got != tt.value {
+			t.Errorf("readVarInt(% x) = %d, %v, want %d", tt.encoded, got, err, tt.value)
+		}
+	}
+}
+
+func TestReadVarIntInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{},
+		{0x80},
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+	} {
+		if _, err := readVarInt(bytes.NewReader(data)); err == nil {
+			t.Errorf("readVarInt(% x) succeeded, want error", data)
+		}
+	}
+}
+
+func TestChatText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"string", `"A Minecraft Server"`, "A Minecraft Server"},
+		{"component", `{"text":"Hello"}`, "Hello"},
+		{"extra", `{"text":"Hello ","extra":[{"text":"world"},"!"]}`, "Hello world!"},
+		{"nested", `{"text":"","extra":[{"text":"a","extra":[{"text":"b"}]},{"text":"c"}]}`, "abc"},
+		{"invalid", `42`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chatText(json.RawMessage(tt.raw)); got != tt.want {
+				t.Errorf("chatText(%s) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+// slpServer runs a fake Minecraft server answering one Server List Ping with
+// status, and the ping if answerPing is set
+func slpServer(t *testing.T, status string, statusID int32, answerPing bool) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		handshake, err := readPacket(r, mcPacketStatus)
+		if err != nil {
+			return
+		}
+		if protocol, _ := readVarInt(handshake); protocol != mcProtocolAny {
+			return
+		}
+		if _, err := readPacket(r, mcPacketStatus); err != nil {
+			return
+		}
+
+		var payload bytes.Buffer
+		writeVarInt(&payload, statusID)
+		writeVarInt(&payload, int32(len(status)))
+		payload.WriteString(status)
+		if writePacket(conn, payload.Bytes()) != nil || !answerPing {
+			return
+		}
+
+		ping, err := readPacket(r, mcPacketPing)
+		if err != nil {
+			return
+		}
+		var pong bytes.Buffer
+		writeVarInt(&pong, mcPacketPing)
+		pong.Write(ping.Bytes())
+		writePacket(conn, pong.Bytes())
+	}()
+	return l.Addr().String()
+}
+
+func TestQueryMinecraft(t *testing.T) {
+	const status = `{
+		"version": {"name": "§a1.21.4", "protocol": 769},
+		"players": {"max": 20, "online": 2, "sample": [{"name": "Alex", "id": "1"}, {"name": "Steve", "id": "2"}]},
+		"description": {"text": "§bA ", "extra": [{"text": "Minecraft  Server"}]},
+		"favicon": "data:image/png;base64,AAAA"
+	}`
+
+	tests := []struct {
+		name       string
+		answerPing bool
+	}{
+		{"with ping", true},
+		{"without ping", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := slpServer(t, status, mcPacketStatus, tt.answerPing)
+			result, err := Query(Minecraft, addr, 2*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := Result{
+				Version:     "1.21.4",
+				MOTD:        "A Minecraft Server",
+				Players:     2,
+				MaxPlayers:  20,
+				PlayerNames: []string{"Alex", "Steve"},
+			}
+			got := *result
+			got.Latency = 0
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Query() = %+v, want %+v", got, want)
+			}
+			if tt.answerPing != (result.Latency > 0) {
+				t.Errorf("Latency = %v with answerPing %v", result.Latency, tt.answerPing)
+			}
+		})
+	}
+}
+
+func TestQueryMinecraftInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		statusID int32
+		wantErr  string
+	}{
+		{"wrong packet", `{}`, 0x05, "unexpected packet 0x05"},
+		{"bad json", `{"version":`, mcPacketStatus, "invalid status response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := slpServer(t, tt.status, tt.statusID, false)
+			_, err := Query(Minecraft, addr, 2*time.Second)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Query() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
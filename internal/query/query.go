@@ -0,0 +1,55 @@
+// Package query asks running game servers for their status using the query
+// protocols games implement, such as Minecraft's Server List Ping and Valve's A2S.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Supported query protocols
+const (
+	// Minecraft is the Minecraft Java Edition Server List Ping over TCP
+	Minecraft = "minecraft"
+	// A2S is the Valve Source server query (A2S_INFO and A2S_PLAYER) over UDP
+	A2S = "a2s"
+)
+
+// Result is what a game server reports about itself
+type Result struct {
+	Version    string
+	MOTD       string
+	Players    int
+	MaxPlayers int
+	// PlayerNames lists online players, if the server shares them (may be partial)
+	PlayerNames []string
+	// Latency is the round trip time of a single request
+	Latency time.Duration
+}
+
+// formatCodes matches Minecraft's § formatting codes, which terminals can't display
+var formatCodes = regexp.MustCompile(`§.`)
+
+// Query asks the server at addr (host:port) for its status using protocol
+func Query(protocol, addr string, timeout time.Duration) (*Result, error) {
+	switch protocol {
+	case Minecraft:
+		return queryMinecraft(addr, timeout)
+	case A2S:
+		return queryA2S(addr, timeout)
+	default:
+		return nil, fmt.Errorf("unsupported query protocol '%s'", protocol)
+	}
+}
+
+// StripFormatting removes Minecraft's § formatting codes from s
+func StripFormatting(s string) string {
+	return formatCodes.ReplaceAllString(s, "")
+}
+
+// cleanText strips formatting codes and collapses whitespace for one-line display
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(StripFormatting(s)), " ")
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStripFormatting(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"§aGreen §lbold§r text", "Green bold text"},
+		{"§§x", "x"},
+		{"trailing §", "trailing §"},
+		{"multi\n§6line", "multi\nline"},
+	}
+	for _, tt := range tests {
+		if got := StripFormatting(tt.in); got != tt.want {
+			t.Errorf("StripFormatting(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  A   Minecraft\nServer ", "A Minecraft Server"},
+		{"§bWelcome§r\n  §7to the server", "Welcome to the server"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := cleanText(tt.in); got != tt.want {
+			t.Errorf("cleanText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQueryUnsupported(t *testing.T) {
+	_, err := Query("gopher", "127.0.0.1:1", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "unsupported query protocol") {
+		t.Errorf("Query() error = %v, want unsupported protocol", err)
+	}
+}
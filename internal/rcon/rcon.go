@@ -0,0 +1,193 @@
+// Package rcon implements a client for the Source RCON protocol, which most
+// game servers (including Minecraft) use for remote administration.
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Packet types. SERVERDATA_AUTH_RESPONSE and SERVERDATA_EXECCOMMAND share a value.
+const (
+	typeResponseValue = 0
+	typeExecCommand   = 2
+	typeAuthResponse  = 2
+	typeAuth          = 3
+)
+
+const (
+	// maxPacketSize is the largest packet accepted from a server
+	maxPacketSize = 4096 + 10
+	// minPacketSize is the size of a packet with an empty body
+	minPacketSize = 10
+	// maxCommandLength is the largest command body servers reliably accept
+	maxCommandLength = 1446
+)
+
+// ErrAuthFailed is returned when the server rejects the RCON password
+var ErrAuthFailed = errors.New("authentication failed (wrong RCON password)")
+
+// Client is an authenticated RCON connection
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	nextID  int32
+}
+
+type packet struct {
+	id   int32
+	typ  int32
+	body string
+}
+
+// Dial connects to an RCON server and authenticates with password
+func Dial(addr, password string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{conn: conn, reader: bufio.NewReader(conn), timeout: timeout, nextID: 1}
+	if err := c.auth(password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// auth sends the password and waits for the auth response. Source servers send
+// an empty response value first, which is skipped.
+func (c *Client) auth(password string) error {
+	id := c.id()
+	if err := c.write(packet{id: id, typ: typeAuth, body: password}); err != nil {
+		return err
+	}
+
+	for {
+		p, err := c.read()
+		if err != nil {
+			return err
+		}
+		if p.typ != typeAuthResponse {
+			continue
+		}
+		if p.id == -1 {
+			return ErrAuthFailed
+		}
+		if p.id != id {
+			return fmt.Errorf("unexpected auth response id %d", p.id)
+		}
+		return nil
+	}
+}
+
+// Execute runs a command and returns its output. Responses split over several
+// packets are reassembled by sending an empty sentinel packet after the command:
+// servers answer in order, so everything before the sentinel's reply belongs to
+// the command.
+func (c *Client) Execute(command string) (string, error) {
+	if len(command) > maxCommandLength {
+		return "", fmt.Errorf("command too long (max %d bytes)", maxCommandLength)
+	}
+
+	id := c.id()
+	sentinel := c.id()
+	if err := c.write(packet{id: id, typ: typeExecCommand, body: command}); err != nil {
+		return "", err
+	}
+	if err := c.write(packet{id: sentinel, typ: typeResponseValue}); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for {
+		p, err := c.read()
+		if err != nil {
+			return "", err
+		}
+		switch p.id {
+		case id:
+			out.WriteString(p.body)
+		case sentinel:
+			return out.String(), nil
+		case -1:
+			return "", ErrAuthFailed
+		}
+		// Anything else is a late reply to an earlier sentinel
+	}
+}
+
+// id returns a new request ID
+func (c *Client) id() int32 {
+	id := c.nextID
+	c.nextID++
+	if c.nextID <= 0 {
+		c.nextID = 1
+	}
+	return id
+}
+
+// write sends a packet: size, id, type, a null-terminated body and an empty string
+func (c *Client) write(p packet) error {
+	var buf bytes.Buffer
+	size := int32(len(p.body) + minPacketSize)
+	_ = binary.Write(&buf, binary.LittleEndian, size)
+	_ = binary.Write(&buf, binary.LittleEndian, p.id)
+	_ = binary.Write(&buf, binary.LittleEndian, p.typ)
+	buf.WriteString(p.body)
+	buf.Write([]byte{0, 0})
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(buf.Bytes())
+	return err
+}
+
+// read receives one packet
+func (c *Client) read() (packet, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return packet{}, err
+	}
+
+	var size int32
+	if err := binary.Read(c.reader, binary.LittleEndian, &size); err != nil {
+		return packet{}, readErr(err)
+	}
+	if size < minPacketSize || size > maxPacketSize {
+		return packet{}, fmt.Errorf("invalid packet size %d", size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return packet{}, readErr(err)
+	}
+
+	body := data[8 : size-2]
+	return packet{
+		id:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		typ:  int32(binary.LittleEndian.Uint32(data[4:8])),
+		body: string(bytes.TrimRight(body, "\x00")),
+	}, nil
+}
+
+// readErr explains a closed connection, which is how some servers reject a password
+func readErr(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("connection closed by server")
+	}
+	return err
+}
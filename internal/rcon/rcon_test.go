@@ -0,0 +1,278 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encode frames a packet by hand, independently of Client.write
+func encode(id, typ int32, body string) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(len(body)+10))
+	binary.Write(&buf, binary.LittleEndian, id)
+	binary.Write(&buf, binary.LittleEndian, typ)
+	buf.WriteString(body)
+	buf.Write([]byte{0, 0})
+	return buf.Bytes()
+}
+
+// decode reads one packet sent by the client
+func decode(r io.Reader) (packet, error) {
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return packet{}, err
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return packet{}, err
+	}
+	return packet{
+		id:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		typ:  int32(binary.LittleEndian.Uint32(data[4:8])),
+		body: string(data[8 : size-2]),
+	}, nil
+}
+
+// pipeClient returns a client connected to a fake server run by serve
+func pipeClient(t *testing.T, serve func(conn net.Conn)) *Client {
+	t.Helper()
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		serve(server)
+	}()
+	t.Cleanup(func() { client.Close() })
+	return &Client{conn: client, reader: bufio.NewReader(client), timeout: time.Second, nextID: 1}
+}
+
+func TestWrite(t *testing.T) {
+	got := make(chan []byte, 1)
+	c := pipeClient(t, func(conn net.Conn) {
+		buf := make([]byte, 64)
+		n, _ := io.ReadAtLeast(conn, buf, 18)
+		got <- buf[:n]
+	})
+
+	if err := c.write(packet{id: 7, typ: typeExecCommand, body: "list"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		14, 0, 0, 0, // size: id + type + body + two nulls
+		7, 0, 0, 0,
+		2, 0, 0, 0,
+		'l', 'i', 's', 't', 0, 0,
+	}
+	if b := <-got; !bytes.Equal(b, want) {
+		t.Errorf("wrote % x, want % x", b, want)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    packet
+		wantErr string
+	}{
+		{"packet", encode(3, typeResponseValue, "There are 0 players"), packet{id: 3, typ: typeResponseValue, body: "There are 0 players"}, ""},
+		{"empty body", encode(-1, typeAuthResponse, ""), packet{id: -1, typ: typeAuthResponse}, ""},
+		{"extra nulls trimmed", encode(1, typeResponseValue, "ok\x00"), packet{id: 1, body: "ok"}, ""},
+		{"too small", []byte{9, 0, 0, 0}, packet{}, "invalid packet size 9"},
+		{"too large", []byte{0xff, 0x10, 0, 0}, packet{}, "invalid packet size"},
+		{"negative size", []byte{0xff, 0xff, 0xff, 0xff}, packet{}, "invalid packet size -1"},
+		{"truncated", encode(1, typeResponseValue, "hello")[:12], packet{}, "connection closed by server"},
+		{"closed", nil, packet{}, "connection closed by server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := pipeClient(t, func(conn net.Conn) {
+				conn.Write(tt.data)
+			})
+			p, err := c.read()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("read() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != tt.want {
+				t.Errorf("read() = %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+}
+
+// listen runs a fake RCON server that accepts one connection
+func listen(t *testing.T, serve func(conn net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(conn)
+	}()
+	return l.Addr().String()
+}
+
+func TestDial(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   func(id int32) []byte
+		wantErr error
+	}{
+		{"accepted", func(id int32) []byte { return encode(id, typeAuthResponse, "") }, nil},
+		{
+			// Source servers send an empty response value before the auth response
+			"accepted after response value",
+			func(id int32) []byte {
+				return append(encode(id, typeResponseValue, ""), encode(id, typeAuthResponse, "")...)
+			},
+			nil,
+		},
+		{"wrong password", func(id int32) []byte { return encode(-1, typeAuthResponse, "") }, ErrAuthFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := listen(t, func(conn net.Conn) {
+				p, err := decode(conn)
+				if err != nil || p.typ != typeAuth || p.body != "secret" {
+					return
+				}
+				conn.Write(tt.reply(p.id))
+				io.Copy(io.Discard, conn)
+			})
+
+			c, err := Dial(addr, "secret", time.Second)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Dial() error = %v, want %v", err, tt.wantErr)
+			}
+			if c != nil {
+				c.Close()
+			}
+		})
+	}
+}
+
+func TestDialClosed(t *testing.T) {
+	// Some servers drop the connection instead of answering a wrong password
+	addr := listen(t, func(conn net.Conn) {
+		decode(conn)
+	})
+	if _, err := Dial(addr, "secret", time.Second); err == nil || !strings.Contains(err.Error(), "connection closed") {
+		t.Errorf("Dial() error = %v, want connection closed", err)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   func(cmd, sentinel packet) [][]byte
+		want    string
+		wantErr error
+	}{
+		{
+			"single packet",
+			func(cmd, sentinel packet) [][]byte {
+				return [][]byte{encode(cmd.id, typeResponseValue, "pong"), encode(sentinel.id, typeResponseValue, "")}
+			},
+			"pong", nil,
+		},
+		{
+			"split response",
+			func(cmd, sentinel packet) [][]byte {
+				return [][]byte{
+					encode(cmd.id, typeResponseValue, "first half, "),
+					encode(cmd.id, typeResponseValue, "second half"),
+					encode(sentinel.id, typeResponseValue, ""),
+				}
+			},
+			"first half, second half", nil,
+		},
+		{
+			"late reply to earlier sentinel",
+			func(cmd, sentinel packet) [][]byte {
+				return [][]byte{
+					encode(cmd.id-1, typeResponseValue, ""),
+					encode(cmd.id, typeResponseValue, "done"),
+					encode(sentinel.id, typeResponseValue, ""),
+				}
+			},
+			"done", nil,
+		},
+		{
+			"empty output",
+			func(cmd, sentinel packet) [][]byte {
+				return [][]byte{encode(sentinel.id, typeResponseValue, "")}
+			},
+			"", nil,
+		},
+		{
+			"session rejected",
+			func(cmd, sentinel packet) [][]byte {
+				return [][]byte{encode(-1, typeResponseValue, "")}
+			},
+			"", ErrAuthFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := pipeClient(t, func(conn net.Conn) {
+				cmd, err := decode(conn)
+				if err != nil || cmd.typ != typeExecCommand || cmd.body != "ping" {
+					return
+				}
+				sentinel, err := decode(conn)
+				if err != nil || sentinel.typ != typeResponseValue || sentinel.body != "" {
+					return
+				}
+				for _, b := range tt.reply(cmd, sentinel) {
+					conn.Write(b)
+				}
+			})
+			c.nextID = 5
+
+			got, err := c.Execute("ping")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteTooLong(t *testing.T) {
+	c := &Client{}
+	if _, err := c.Execute(strings.Repeat("x", maxCommandLength+1)); err == nil {
+		t.Error("Execute() accepted an oversized command")
+	}
+}
+
+func TestIDWraps(t *testing.T) {
+	c := &Client{nextID: 1<<31 - 1}
+	if id := c.id(); id != 1<<31-1 {
+		t.Errorf("id() = %d, want %d", id, int32(1<<31-1))
+	}
+	// IDs stay positive, -1 means authentication failed
+	if id := c.id(); id != 1 {
+		t.Errorf("id() after wrap = %d, want 1", id)
+	}
+}
@@ -0,0 +1,310 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hostathome/cli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// metaSuffix names the metadata file stored next to each cached registry file
+const metaSuffix = ".meta.json"
+
+// ErrOffline is returned for remote registry files that aren't cached in offline mode
+var ErrOffline = errors.New("not cached (offline mode)")
+
+var (
+	cacheTTL = defaultCacheTTL
+	// revalidate makes cached files be checked with the registry regardless of their age
+	revalidate bool
+	// offline restricts remote registries to cached files
+	offline bool
+)
+
+// SetOffline makes remote registries serve only cached files, however old,
+// without network access. Local registries are still read.
+func SetOffline(enabled bool) {
+	offline = enabled
+}
+
+// Offline reports whether offline mode is enabled
+func Offline() bool {
+	return offline
+}
+
+// SetCacheTTL sets how long cached registry files are used without asking the
+// registry whether they changed. Zero checks on every run.
+func SetCacheTTL(ttl time.Duration) {
+	cacheTTL = ttl
+}
+
+// CacheTTL returns how long cached registry files are considered fresh
+func CacheTTL() time.Duration {
+	return cacheTTL
+}
+
+// Revalidate makes the following fetches check every cached file with its
+// registry, as if the cache had expired
+func Revalidate() {
+	revalidate = true
+}
+
+// cacheMeta describes a cached registry file
+type cacheMeta struct {
+	// Path is the file's path in the registry, e.g. "games/minecraft.yaml"
+	Path         string `json:"path"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// Checked is when the cached copy was last confirmed current by the registry
+	Checked time.Time `json:"checked"`
+}
+
+// CacheEntry is a registry file in the local cache
+type CacheEntry struct {
+	// Path is the file's path in the registry, e.g. "games/minecraft.yaml"
+	Path    string    `json:"path" yaml:"path"`
+	Size    int64     `json:"size" yaml:"size"`
+	Checked time.Time `json:"checked" yaml:"checked"`
+	// Fresh entries are used without contacting the registry
+	Fresh        bool   `json:"fresh" yaml:"fresh"`
+	ETag         string `json:"etag,omitempty" yaml:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty" yaml:"last_modified,omitempty"`
+}
+
+// fetchCached returns a registry file, from the cache while it is fresh.
+// Expired files are revalidated with a conditional request, and stale copies
+// are used if the registry can't be reached or in offline mode. Local
+// registries are read directly.
+// With force, cached files are revalidated regardless of age.
+func (r *Registry) fetchCached(ctx context.Context, file string, force bool) ([]byte, error) {
+	cacheDir := r.CacheDir()
+	if r.Source.Local() || cacheDir == "" {
+		return r.Source.Fetch(ctx, file)
+	}
+	// Mirror the registry layout so e.g. games/index.yaml can't replace index.yaml
+	cacheFile := filepath.Join(cacheDir, filepath.FromSlash(file))
+	if _, err := os.Stat(cacheFile); errors.Is(err, os.ErrNotExist) {
+		r.migrateLegacyCache(file, cacheFile)
+	}
+
+	cached, readErr := os.ReadFile(cacheFile)
+	if offline {
+		if readErr != nil {
+			return nil, fmt.Errorf("%s %w", file, ErrOffline)
+		}
+		return cached, nil
+	}
+	meta := readCacheMeta(cacheFile)
+	if readErr == nil && !revalidate && !force && time.Since(meta.Checked) < cacheTTL {
+		return cached, nil
+	}
+
+	var known Validators
+	if readErr == nil {
+		known = Validators{ETag: meta.ETag, LastModified: meta.LastModified}
+	}
+	data, validators, err := fetchIfChanged(ctx, r.Source, file, known)
+	if errors.Is(err, ErrNotModified) {
+		meta.Checked = time.Now()
+		writeCacheMeta(cacheFile, meta)
+		return cached, nil
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		// Fall back to stale cache if available
+		if readErr == nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	// Save to cache (non-critical, don't fail if it fails)
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err == nil {
+		if err := os.WriteFile(cacheFile, data, 0644); err == nil {
+			writeCacheMeta(cacheFile, cacheMeta{
+				Path:         file,
+				ETag:         validators.ETag,
+				LastModified: validators.LastModified,
+				Checked:      time.Now(),
+			})
+		}
+	}
+
+	return data, nil
+}
+
+// legacyIndexFile is the game index as cached by earlier versions, a JSON list of names
+const legacyIndexFile = "index.json"
+
+// migrateLegacyCache moves a registry file cached by earlier versions to
+// cacheFile, so upgrading doesn't lose the cache needed for offline use.
+// Earlier versions kept game definitions by base name and the game index as
+// JSON, in the registry's cache directory or, for the default registry,
+// directly in the cache root. Migration is non-critical.
+func (r *Registry) migrateLegacyCache(file, cacheFile string) {
+	var name string
+	switch {
+	case file == indexFile:
+		name = legacyIndexFile
+	case path.Dir(file) == "games" && path.Base(file) != indexFile:
+		name = path.Base(file)
+	default:
+		return
+	}
+
+	dirs := []string{r.CacheDir()}
+	if r.Source.String() == DefaultRegistry {
+		if root, err := config.GetCacheDir(); err == nil {
+			dirs = append(dirs, root)
+		}
+	}
+	for _, dir := range dirs {
+		legacy := filepath.Join(dir, name)
+		info, err := os.Stat(legacy)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+			return
+		}
+
+		if name != legacyIndexFile {
+			_ = os.Rename(legacy, cacheFile)
+			return
+		}
+		data, err := os.ReadFile(legacy)
+		if err != nil {
+			return
+		}
+		var index struct {
+			Games []string `yaml:"games"`
+		}
+		if json.Unmarshal(data, &index.Games) != nil {
+			return
+		}
+		if data, err = yaml.Marshal(index); err != nil {
+			return
+		}
+		if os.WriteFile(cacheFile, data, 0644) == nil {
+			// Keep the age of the cached copy
+			_ = os.Chtimes(cacheFile, info.ModTime(), info.ModTime())
+			_ = os.Remove(legacy)
+		}
+		return
+	}
+}
+
+// fetchIfChanged fetches a file, conditionally if the source supports it
+func fetchIfChanged(ctx context.Context, src Source, file string, known Validators) ([]byte, Validators, error) {
+	if cs, ok := src.(ConditionalSource); ok {
+		return cs.FetchIfChanged(ctx, file, known)
+	}
+	data, err := src.Fetch(ctx, file)
+	return data, Validators{}, err
+}
+
+// readCacheMeta reads the metadata of a cached file. Files cached before
+// metadata was kept count as checked when they were written.
+func readCacheMeta(cacheFile string) cacheMeta {
+	if data, err := os.ReadFile(cacheFile + metaSuffix); err == nil {
+		var meta cacheMeta
+		if json.Unmarshal(data, &meta) == nil {
+			return meta
+		}
+	}
+	var meta cacheMeta
+	if info, err := os.Stat(cacheFile); err == nil {
+		meta.Checked = info.ModTime()
+	}
+	return meta
+}
+
+// writeCacheMeta saves the metadata of a cached file (non-critical)
+func writeCacheMeta(cacheFile string, meta cacheMeta) {
+	if data, err := json.MarshalIndent(meta, "", "  "); err == nil {
+		_ = os.WriteFile(cacheFile+metaSuffix, data, 0644)
+	}
+}
+
+// CacheEntries lists the files cached for this registry, index first.
+// Local registries have no cache.
+func (r *Registry) CacheEntries() ([]CacheEntry, error) {
+	if r.Source.Local() {
+		return nil, nil
+	}
+
+	dir := r.CacheDir()
+	if dir == "" {
+		return nil, nil
+	}
+
+	var entries []CacheEntry
+	err := filepath.WalkDir(dir, func(file string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".yaml") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil
+		}
+		meta := readCacheMeta(file)
+		entries = append(entries, CacheEntry{
+			Path:         filepath.ToSlash(rel),
+			Size:         info.Size(),
+			Checked:      meta.Checked,
+			Fresh:        time.Since(meta.Checked) < cacheTTL,
+			ETag:         meta.ETag,
+			LastModified: meta.LastModified,
+		})
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Path == indexFile && entries[j].Path != indexFile
+	})
+	return entries, nil
+}
+
+// ClearCache deletes the cached files of every registry, including registries
+// that are no longer configured
+func ClearCache() error {
+	cacheDir, err := config.GetCacheDir()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(cacheDir, e.Name())); err != nil {
+			return err
+		}
+	}
+	loadedGames.clear()
+	return nil
+}
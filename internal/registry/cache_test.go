@@ -0,0 +1,256 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hostathome/cli/internal/config"
+)
+
+// testServer serves one registry file with an ETag and Last-Modified, and
+// records the conditional headers of each request
+type testServer struct {
+	mu       sync.Mutex
+	body     string
+	etag     string
+	status   int
+	requests []http.Header
+}
+
+const lastModified = "Wed, 12 Mar 2025 12:00:00 GMT"
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Header.Clone())
+
+	switch {
+	case s.status != 0:
+		w.WriteHeader(s.status)
+	case r.URL.Path != "/games/minecraft.yaml":
+		w.WriteHeader(http.StatusNotFound)
+	case r.Header.Get("If-None-Match") == s.etag:
+		w.WriteHeader(http.StatusNotModified)
+	default:
+		w.Header().Set("ETag", s.etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(s.body))
+	}
+}
+
+func (s *testServer) update(body, etag string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag, s.status = body, etag, status
+}
+
+// lastRequest returns the headers of the last request, or nil if there were
+// no requests since the previous call
+func (s *testServer) lastRequest() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		return nil
+	}
+	last := s.requests[len(s.requests)-1]
+	s.requests = nil
+	return last
+}
+
+// setupCache points the cache at a temporary home and restores the cache
+// settings after the test
+func setupCache(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Cleanup(func() {
+		cacheTTL, revalidate, offline = defaultCacheTTL, false, false
+	})
+	cacheTTL = time.Hour
+}
+
+func TestFetchCached(t *testing.T) {
+	setupCache(t)
+	srv := &testServer{body: "name: minecraft\n", etag: `"v1"`}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	r := &Registry{Name: "test", Source: &httpSource{baseURL: ts.URL}}
+	ctx := context.Background()
+	const file = "games/minecraft.yaml"
+
+	steps := []struct {
+		name  string
+		setup func()
+		want  string
+		// request is whether the registry must be contacted
+		request bool
+		// ifNoneMatch is the expected conditional request header
+		ifNoneMatch string
+		wantErr     error
+	}{
+		{name: "first fetch", want: "name: minecraft\n", request: true},
+		{name: "fresh", want: "name: minecraft\n"},
+		{
+			name:        "expired not modified",
+			setup:       func() { cacheTTL = 0 },
+			want:        "name: minecraft\n",
+			request:     true,
+			ifNoneMatch: `"v1"`,
+		},
+		{
+			name:        "expired changed",
+			setup:       func() { srv.update("name: minecraft\nimage: v2\n", `"v2"`, 0) },
+			want:        "name: minecraft\nimage: v2\n",
+			request:     true,
+			ifNoneMatch: `"v1"`,
+		},
+		{
+			name:        "stale on error",
+			setup:       func() { srv.update("", `"v3"`, http.StatusInternalServerError) },
+			want:        "name: minecraft\nimage: v2\n",
+			request:     true,
+			ifNoneMatch: `"v2"`,
+		},
+		{
+			name:  "offline",
+			setup: func() { offline = true },
+			want:  "name: minecraft\nimage: v2\n",
+		},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.setup != nil {
+				step.setup()
+			}
+			data, err := r.fetchCached(ctx, file, false)
+			if err != nil {
+				t.Fatalf("fetchCached() error = %v", err)
+			}
+			if string(data) != step.want {
+				t.Errorf("fetchCached() = %q, want %q", data, step.want)
+			}
+
+			header := srv.lastRequest()
+			if (header != nil) != step.request {
+				t.Fatalf("registry contacted = %v, want %v", header != nil, step.request)
+			}
+			if header == nil {
+				return
+			}
+			if got := header.Get("If-None-Match"); got != step.ifNoneMatch {
+				t.Errorf("If-None-Match = %q, want %q", got, step.ifNoneMatch)
+			}
+			wantSince := ""
+			if step.ifNoneMatch != "" {
+				wantSince = lastModified
+			}
+			if got := header.Get("If-Modified-Since"); got != wantSince {
+				t.Errorf("If-Modified-Since = %q, want %q", got, wantSince)
+			}
+		})
+	}
+
+	meta := readCacheMeta(filepath.Join(r.CacheDir(), "games", "minecraft.yaml"))
+	if meta.ETag != `"v2"` || meta.LastModified != lastModified || meta.Path != file {
+		t.Errorf("cache metadata = %+v", meta)
+	}
+}
+
+func TestFetchCachedErrors(t *testing.T) {
+	setupCache(t)
+	srv := &testServer{body: "name: minecraft\n", etag: `"v1"`}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	r := &Registry{Name: "test", Source: &httpSource{baseURL: ts.URL}}
+	ctx := context.Background()
+
+	if _, err := r.fetchCached(ctx, "games/valheim.yaml", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing file error = %v, want ErrNotFound", err)
+	}
+
+	srv.update("", "", http.StatusInternalServerError)
+	if _, err := r.fetchCached(ctx, "games/minecraft.yaml", false); err == nil {
+		t.Error("uncached file on server error succeeded")
+	}
+
+	offline = true
+	srv.lastRequest()
+	if _, err := r.fetchCached(ctx, "games/minecraft.yaml", false); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline error = %v, want ErrOffline", err)
+	}
+	if srv.lastRequest() != nil {
+		t.Error("registry contacted in offline mode")
+	}
+}
+
+func TestFetchCachedLegacy(t *testing.T) {
+	tests := []struct {
+		name string
+		// root writes the legacy files to the cache root instead of the registry's directory
+		root     bool
+		location string
+	}{
+		{"default registry", true, DefaultRegistry},
+		{"registry directory", false, "https://registry.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupCache(t)
+			offline = true
+
+			r := &Registry{Name: "test", Source: &httpSource{baseURL: tt.location}}
+			legacyDir := r.CacheDir()
+			if tt.root {
+				var err error
+				if legacyDir, err = config.GetCacheDir(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.MkdirAll(legacyDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			files := map[string]string{
+				"minecraft.yaml": "name: minecraft\n",
+				"index.json":     `["minecraft","valheim"]`,
+			}
+			for name, data := range files {
+				if err := os.WriteFile(filepath.Join(legacyDir, name), []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx := context.Background()
+			data, err := r.fetchCached(ctx, "games/minecraft.yaml", false)
+			if err != nil {
+				t.Fatalf("game: %v", err)
+			}
+			if string(data) != files["minecraft.yaml"] {
+				t.Errorf("game = %q", data)
+			}
+
+			index, err := r.fetchGameIndex(ctx)
+			if err != nil {
+				t.Fatalf("index: %v", err)
+			}
+			if len(index) != 2 || index[0] != "minecraft" || index[1] != "valheim" {
+				t.Errorf("index = %v", index)
+			}
+
+			for name := range files {
+				if _, err := os.Stat(filepath.Join(legacyDir, name)); !os.IsNotExist(err) {
+					t.Errorf("legacy %s not migrated", name)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package registry
 import (
 	"archive/tar"
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +14,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -21,14 +24,92 @@ import (
 )
 
 const (
-	registryBaseURL   = "https://raw.githubusercontent.com/hostathome/registry/main"
-	registryGamesURL  = registryBaseURL + "/games"
-	cacheTTL          = 1 * time.Hour
-	httpTimeout       = 30  // seconds
-	dockerOpTimeout   = 30  // seconds
+	// DefaultRegistry is the public HostAtHome game registry
+	DefaultRegistry = "https://raw.githubusercontent.com/hostathome/registry/main"
+
+	defaultCacheTTL = 1 * time.Hour
+	httpTimeout     = 30 // seconds
+	dockerOpTimeout = 30 // seconds
+
+	// listConcurrency bounds the game definitions fetched at once by ListGames
+	listConcurrency = 8
+	// listTimeout is the overall deadline for ListGames
+	listTimeout = 60 * time.Second
+
+	// indexFile lists the games in a registry
+	indexFile = "index.yaml"
+)
+
+// Registry is a named game registry. Registries are consulted in order, so a
+// game in an earlier registry shadows a game with the same name in a later one.
+type Registry struct {
+	Name   string
+	Source Source
+}
+
+var (
+	loadedGames gameCache
+	registries  []*Registry
 )
 
-var gameCache = make(map[string]*Game)
+// gameCache holds the game definitions loaded during this run. It is safe for
+// concurrent use.
+type gameCache struct {
+	mu    sync.RWMutex
+	games map[string]*Game
+}
+
+func (c *gameCache) get(name string) (*Game, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	game, ok := c.games[name]
+	return game, ok
+}
+
+func (c *gameCache) set(name string, game *Game) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.games == nil {
+		c.games = make(map[string]*Game)
+	}
+	c.games[name] = game
+}
+
+func (c *gameCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.games = nil
+}
+
+// FetchError is a game definition or game index that couldn't be loaded
+type FetchError struct {
+	Registry string
+	// Game is empty if the registry's game index failed
+	Game string
+	Err  error
+}
+
+func (e *FetchError) Error() string {
+	if e.Game == "" {
+		return fmt.Sprintf("registry '%s': %v", e.Registry, e.Err)
+	}
+	return fmt.Sprintf("game '%s' from registry '%s': %v", e.Game, e.Registry, e.Err)
+}
+
+func (e *FetchError) Unwrap() error { return e.Err }
+
+// PartialError is returned by ListGames along with the games that could be
+// loaded when others couldn't
+type PartialError struct {
+	Errors []*FetchError
+}
+
+func (e *PartialError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d registry entries could not be loaded", len(e.Errors))
+}
 
 // validateGameName checks if gameName is valid for use in paths
 func validateGameName(gameName string) error {
@@ -54,179 +135,239 @@ func getHTTPClient() *http.Client {
 	}
 }
 
-// getCacheDir returns the full path to the cache directory
-func getCacheDir() string {
+// Configure sets the ordered list of registries used by GetGame and ListGames.
+// Entries without a URL point at the public registry.
+func Configure(entries []config.RegistryEntry) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("at least one registry is required")
+	}
+
+	var regs []*Registry
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if e.Name == "" {
+			return fmt.Errorf("registry %s has no name", e.URL)
+		}
+		if seen[e.Name] {
+			return fmt.Errorf("duplicate registry name '%s'", e.Name)
+		}
+		seen[e.Name] = true
+
+		location := e.URL
+		if location == "" {
+			location = DefaultRegistry
+		}
+		src, err := NewSource(location)
+		if err != nil {
+			return fmt.Errorf("registry '%s': %w", e.Name, err)
+		}
+		regs = append(regs, &Registry{Name: e.Name, Source: src})
+	}
+
+	registries = regs
+	loadedGames.clear()
+	return nil
+}
+
+// Registries returns the configured registries in priority order,
+// defaulting to the public registry
+func Registries() []*Registry {
+	if len(registries) == 0 {
+		registries = []*Registry{{
+			Name:   config.DefaultRegistryName,
+			Source: &httpSource{baseURL: DefaultRegistry},
+		}}
+	}
+	return registries
+}
+
+// CacheDir returns the cache directory for a registry.
+// Each source gets its own subdirectory so mirrors don't overwrite each other.
+func (r *Registry) CacheDir() string {
 	cacheDir, err := config.GetCacheDir()
 	if err != nil {
 		return ""
 	}
-	return cacheDir
+	sum := sha256.Sum256([]byte(r.Source.String()))
+	return filepath.Join(cacheDir, hex.EncodeToString(sum[:])[:12])
 }
 
-// GetGame returns a game definition by name
+// GetGame returns a game definition by name from the first registry that has it.
+// Lower-priority registries are only tried if a registry doesn't have the game:
+// when its definition can't be loaded, falling back would silently swap in a
+// different game and image.
 func GetGame(name string) (*Game, error) {
-	if game, ok := gameCache[name]; ok {
+	if game, ok := loadedGames.get(name); ok {
 		return game, nil
 	}
 
-	data, err := fetchWithCache(name)
-	if err != nil {
-		return nil, fmt.Errorf("game '%s' not found in registry: %w", name, err)
-	}
-
-	var game Game
-	if err := yaml.Unmarshal(data, &game); err != nil {
-		return nil, fmt.Errorf("failed to parse game definition: %w", err)
+	if err := validateGameName(name); err != nil {
+		return nil, fmt.Errorf("invalid game name: %w", err)
 	}
 
-	gameCache[name] = &game
-	return &game, nil
-}
-
-// fetchWithCache fetches a game definition from GitHub or cache
-func fetchWithCache(name string) ([]byte, error) {
-	cacheFile := filepath.Join(getCacheDir(), name+".yaml")
-
-	// Check if cache exists and is fresh
-	if info, err := os.Stat(cacheFile); err == nil {
-		if time.Since(info.ModTime()) < cacheTTL {
-			return os.ReadFile(cacheFile)
+	for _, reg := range Registries() {
+		game, err := reg.getGame(context.Background(), name)
+		if err == nil {
+			loadedGames.set(name, game)
+			return game, nil
 		}
-	}
-
-	// Fetch from GitHub
-	url := fmt.Sprintf("%s/%s.yaml", registryGamesURL, name)
-	resp, err := getHTTPClient().Get(url)
-	if err != nil {
-		// Fall back to stale cache if available
-		if data, cacheErr := os.ReadFile(cacheFile); cacheErr == nil {
-			return data, nil
+		if !errors.Is(err, ErrNotFound) {
+			return nil, &FetchError{Registry: reg.Name, Game: name, Err: err}
 		}
-		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("game not found")
-	}
-	if resp.StatusCode != 200 {
-		// Fall back to stale cache
-		if data, cacheErr := os.ReadFile(cacheFile); cacheErr == nil {
-			return data, nil
-		}
-		return nil, fmt.Errorf("failed to fetch: %s", resp.Status)
-	}
+	return nil, fmt.Errorf("game '%s' not found in registry: %w", name, ErrNotFound)
+}
 
-	data, err := io.ReadAll(resp.Body)
+// getGame fetches and parses a game definition from this registry only
+func (r *Registry) getGame(ctx context.Context, name string) (*Game, error) {
+	data, err := r.fetchFile(ctx, "games/"+name+".yaml")
 	if err != nil {
 		return nil, err
 	}
 
-	// Save to cache (non-critical, don't fail if it fails)
-	if dir := getCacheDir(); dir != "" {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			// Log but don't fail - cache is optional
-			_ = err
-		} else if err := os.WriteFile(cacheFile, data, 0644); err != nil {
-			// Log but don't fail - cache is optional
-			_ = err
-		}
+	var game Game
+	if err := yaml.Unmarshal(data, &game); err != nil {
+		return nil, fmt.Errorf("failed to parse game definition: %w", err)
+	}
+	// Signatures only cover the contents, so a validly signed definition could
+	// be served under another game's path
+	if game.Name != name {
+		return nil, fmt.Errorf("games/%s.yaml defines game '%s'", name, game.Name)
 	}
+	game.Registry = r.Name
 
-	return data, nil
+	return &game, nil
 }
 
-// ListGames returns all available games
+// ListGames returns all available games across registries. When several
+// registries define the same game, the highest-priority definition is returned
+// with the names of the registries it shadows.
+//
+// Game definitions are fetched concurrently under an overall deadline. If some
+// indexes or games can't be loaded, the others are returned together with a
+// *PartialError; an error without games means no registry could be read.
 func ListGames() ([]Game, error) {
-	index, err := fetchGameIndex()
-	if err != nil {
-		return nil, err
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+	defer cancel()
 
-	var games []Game
-	for _, name := range index {
-		game, err := GetGame(name)
-		if err != nil {
+	regs := Registries()
+	indexes := make([][]string, len(regs))
+	indexErrs := make([]error, len(regs))
+	var wg sync.WaitGroup
+	for i, reg := range regs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			indexes[i], indexErrs[i] = reg.fetchGameIndex(ctx)
+		}()
+	}
+	wg.Wait()
+
+	// A game is fetched from the registries listing it, in priority order,
+	// moving on only when a registry doesn't actually have it
+	type entry struct {
+		name       string
+		candidates []*Registry
+	}
+	var entries []*entry
+	byName := make(map[string]*entry)
+	var failures []*FetchError
+	fetched := 0
+	for i, reg := range regs {
+		if indexErrs[i] != nil {
+			failures = append(failures, &FetchError{Registry: reg.Name, Err: indexErrs[i]})
 			continue
 		}
-		games = append(games, *game)
+		fetched++
+		for _, name := range indexes[i] {
+			if e, ok := byName[name]; ok {
+				e.candidates = append(e.candidates, reg)
+				continue
+			}
+			if validateGameName(name) != nil {
+				failures = append(failures, &FetchError{Registry: reg.Name, Game: name, Err: fmt.Errorf("invalid game name")})
+				continue
+			}
+			e := &entry{name: name, candidates: []*Registry{reg}}
+			byName[name] = e
+			entries = append(entries, e)
+		}
 	}
-	return games, nil
-}
-
-// fetchGameIndex fetches the list of available games
-func fetchGameIndex() ([]string, error) {
-	cacheFile := filepath.Join(getCacheDir(), "index.json")
-
-	// Check cache
-	if info, err := os.Stat(cacheFile); err == nil {
-		if time.Since(info.ModTime()) < cacheTTL {
-			if data, err := os.ReadFile(cacheFile); err == nil {
-				var index []string
-				if err := json.Unmarshal(data, &index); err == nil && len(index) > 0 {
-					return index, nil
+	if fetched == 0 && len(failures) > 0 {
+		return nil, failures[0]
+	}
+
+	results := make([]*Game, len(entries))
+	gameErrs := make([][]*FetchError, len(entries))
+	sem := make(chan struct{}, listConcurrency)
+	for i, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			for j, reg := range e.candidates {
+				game, err := reg.getGame(ctx, e.name)
+				if err != nil {
+					gameErrs[i] = append(gameErrs[i], &FetchError{Registry: reg.Name, Game: e.name, Err: err})
+					if errors.Is(err, ErrNotFound) {
+						continue
+					}
+					return
 				}
+				for _, shadowed := range e.candidates[j+1:] {
+					game.Shadows = append(game.Shadows, shadowed.Name)
+				}
+				results[i] = game
+				return
 			}
-		}
+		}()
 	}
+	wg.Wait()
 
-	// Fetch index.yaml from GitHub
-	url := registryBaseURL + "/index.yaml"
-	resp, err := getHTTPClient().Get(url)
-	if err != nil {
-		// Fall back to cache if available
-		if data, cacheErr := os.ReadFile(cacheFile); cacheErr == nil && len(data) > 0 {
-			var index []string
-			if cacheParseErr := json.Unmarshal(data, &index); cacheParseErr == nil && len(index) > 0 {
-				return index, nil
-			}
+	var games []Game
+	for i, game := range results {
+		failures = append(failures, gameErrs[i]...)
+		if game != nil {
+			loadedGames.set(entries[i].name, game)
+			games = append(games, *game)
 		}
-		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		// Fall back to cache if available
-		if data, cacheErr := os.ReadFile(cacheFile); cacheErr == nil && len(data) > 0 {
-			var index []string
-			if cacheParseErr := json.Unmarshal(data, &index); cacheParseErr == nil && len(index) > 0 {
-				return index, nil
-			}
-		}
-		return nil, fmt.Errorf("failed to fetch game index: %s", resp.Status)
+	if len(failures) > 0 {
+		return games, &PartialError{Errors: failures}
 	}
+	return games, nil
+}
 
-	data, err := io.ReadAll(resp.Body)
+// fetchGameIndex fetches the list of games available in this registry
+func (r *Registry) fetchGameIndex(ctx context.Context) ([]string, error) {
+	data, err := r.fetchFile(ctx, indexFile)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("game index not found in registry %s", r.Source)
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to fetch game index: %w", err)
 	}
 
-	var indexFile struct {
+	var index struct {
 		Games []string `yaml:"games"`
 	}
-	if err := yaml.Unmarshal(data, &indexFile); err != nil {
-		return nil, err
-	}
-
-	// Cache as JSON (non-critical, don't fail if it fails)
-	if dir := getCacheDir(); dir != "" {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			_ = err // Log but don't fail - cache is optional
-		} else if jsonData, err := json.Marshal(indexFile.Games); err == nil {
-			_ = os.WriteFile(cacheFile, jsonData, 0644) // Log but don't fail
-		}
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid game index: %w", err)
 	}
-
-	return indexFile.Games, nil
+	return index.Games, nil
 }
 
-// CopyDefaultConfig extracts default configs from the Docker image
-func CopyDefaultConfig(gameName string, game *Game) error {
-	if err := validateGameName(gameName); err != nil {
-		return fmt.Errorf("invalid game name: %w", err)
+// CopyDefaultConfig extracts default configs from the Docker image into a server instance's directory
+func CopyDefaultConfig(instance string, game *Game) error {
+	if err := validateGameName(instance); err != nil {
+		return fmt.Errorf("invalid instance name: %w", err)
 	}
 
-	serverDir := fmt.Sprintf("./%s-server", gameName)
+	serverDir := fmt.Sprintf("./%s-server", instance)
 	configDir := filepath.Join(serverDir, "configs")
 
 	// Create configs directory if it doesn't exist
@@ -318,4 +459,3 @@ func extractFileFromImage(ctx context.Context, cli *client.Client, imageRef, con
 		}
 	}
 }
-
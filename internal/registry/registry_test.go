@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hostathome/cli/internal/config"
+)
+
+// testRegistry is a local registry directory. A nil index leaves out index.yaml.
+type testRegistry struct {
+	index []string
+	// games maps game names to their image; "!" writes an invalid definition
+	games map[string]string
+}
+
+// write creates the registry in a temporary directory and returns its path
+func (tr testRegistry) write(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "games"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if tr.index != nil {
+		index := "games: [" + strings.Join(tr.index, ", ") + "]\n"
+		if err := os.WriteFile(filepath.Join(dir, indexFile), []byte(index), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, image := range tr.games {
+		data := "name: " + name + "\nimage: " + image + "\n"
+		if image == "!" {
+			data = "name: [" + name
+		}
+		if err := os.WriteFile(filepath.Join(dir, "games", name+".yaml"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestListGames(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   testRegistry
+		secondary testRegistry
+		// want maps game names to "<registry> <image>", followed by the shadowed registries
+		want map[string]string
+		// failed lists the "<registry>/<game>" entries of the partial error
+		failed  []string
+		wantErr bool
+	}{
+		{
+			name:      "shadowing",
+			primary:   testRegistry{[]string{"minecraft", "valheim"}, map[string]string{"minecraft": "mc:primary", "valheim": "vh"}},
+			secondary: testRegistry{[]string{"minecraft", "terraria"}, map[string]string{"minecraft": "mc:secondary", "terraria": "tr"}},
+			want: map[string]string{
+				"minecraft": "primary mc:primary secondary",
+				"valheim":   "primary vh",
+				"terraria":  "secondary tr",
+			},
+		},
+		{
+			name:      "listed but missing falls through",
+			primary:   testRegistry{[]string{"minecraft"}, nil},
+			secondary: testRegistry{[]string{"minecraft"}, map[string]string{"minecraft": "mc:secondary"}},
+			want:      map[string]string{"minecraft": "secondary mc:secondary"},
+			failed:    []string{"primary/minecraft"},
+		},
+		{
+			name:      "index missing",
+			primary:   testRegistry{[]string{"minecraft"}, map[string]string{"minecraft": "mc"}},
+			secondary: testRegistry{nil, map[string]string{"terraria": "tr"}},
+			want:      map[string]string{"minecraft": "primary mc"},
+			failed:    []string{"secondary/"},
+		},
+		{
+			name:      "invalid definition does not fall back",
+			primary:   testRegistry{[]string{"minecraft", "valheim"}, map[string]string{"minecraft": "!", "valheim": "vh"}},
+			secondary: testRegistry{[]string{"minecraft"}, map[string]string{"minecraft": "mc:secondary"}},
+			want:      map[string]string{"valheim": "primary vh"},
+			failed:    []string{"primary/minecraft"},
+		},
+		{
+			name:      "invalid name in index",
+			primary:   testRegistry{[]string{"minecraft", "../etc"}, map[string]string{"minecraft": "mc"}},
+			secondary: testRegistry{[]string{}, nil},
+			want:      map[string]string{"minecraft": "primary mc"},
+			failed:    []string{"primary/../etc"},
+		},
+		{
+			name:      "no index",
+			primary:   testRegistry{},
+			secondary: testRegistry{},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Configure([]config.RegistryEntry{
+				{Name: "primary", URL: tt.primary.write(t)},
+				{Name: "secondary", URL: tt.secondary.write(t)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				registries = nil
+				loadedGames.clear()
+			})
+
+			games, err := ListGames()
+			if tt.wantErr {
+				var partial *PartialError
+				if err == nil || errors.As(err, &partial) || len(games) > 0 {
+					t.Fatalf("ListGames() = %d games, %v; want a complete failure", len(games), err)
+				}
+				return
+			}
+
+			got := make(map[string]string)
+			for _, g := range games {
+				got[g.Name] = strings.Join(append([]string{g.Registry, g.Image}, g.Shadows...), " ")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("games = %v, want %v", got, tt.want)
+			}
+
+			var failed []string
+			if err != nil {
+				var partial *PartialError
+				if !errors.As(err, &partial) {
+					t.Fatalf("ListGames() error = %v, want a *PartialError", err)
+				}
+				for _, e := range partial.Errors {
+					failed = append(failed, e.Registry+"/"+e.Game)
+				}
+			}
+			sort.Strings(failed)
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failed = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
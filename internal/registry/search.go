@@ -0,0 +1,187 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Match scores, highest first. A game scores the best of its fields.
+const (
+	scoreExactName        = 1000
+	scoreExactDisplayName = 900
+	scorePrefix           = 800
+	scoreTag              = 700
+	scoreWordPrefix       = 600
+	scoreSubstring        = 500
+	scoreTypo             = 400
+	scoreDescription      = 300
+	scoreSubsequence      = 100
+)
+
+// Search returns the games matching query that carry every tag in tags, best
+// match first. Names and display names match exactly, by prefix, by substring,
+// with a typo or as a subsequence ("mcft" finds "Minecraft"); descriptions
+// match by substring. An empty query matches every game, in the given order.
+func Search(games []Game, query string, tags []string) []Game {
+	query = normalize(query)
+
+	type match struct {
+		game  Game
+		score int
+	}
+	var matches []match
+	for _, g := range games {
+		if !hasTags(&g, tags) {
+			continue
+		}
+		score := 1
+		if query != "" {
+			score = matchScore(&g, query)
+		}
+		if score > 0 {
+			matches = append(matches, match{game: g, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	results := make([]Game, len(matches))
+	for i, m := range matches {
+		results[i] = m.game
+	}
+	return results
+}
+
+// hasTags reports whether a game carries every tag
+func hasTags(g *Game, tags []string) bool {
+	for _, tag := range tags {
+		if !g.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchScore rates how well a game matches a normalized query, 0 if it doesn't
+func matchScore(g *Game, query string) int {
+	name := normalize(g.Name)
+	displayName := normalize(g.DisplayName)
+
+	switch {
+	case name == query:
+		return scoreExactName
+	case displayName == query:
+		return scoreExactDisplayName
+	case strings.HasPrefix(name, query) || strings.HasPrefix(displayName, query):
+		return scorePrefix
+	case g.HasTag(query):
+		return scoreTag
+	case hasWordPrefix(displayName, query):
+		return scoreWordPrefix
+	case strings.Contains(name, query) || strings.Contains(displayName, query):
+		return scoreSubstring
+	case isTypo(name, query) || isTypo(displayName, query):
+		return scoreTypo
+	case strings.Contains(normalize(g.Description), query):
+		return scoreDescription
+	}
+
+	// Subsequences rank by how tightly the query's letters cluster
+	best := 0
+	for _, field := range []string{name, displayName} {
+		if gaps, ok := subsequenceGaps(field, query); ok {
+			best = max(best, scoreSubsequence-min(gaps, scoreSubsequence-1))
+		}
+	}
+	return best
+}
+
+// normalize lowercases s and turns separators into single spaces, so
+// "Counter-Strike_2" and "counter strike 2" compare equal
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// hasWordPrefix reports whether a word of s, other than the first, starts with query
+func hasWordPrefix(s, query string) bool {
+	words := strings.Fields(s)
+	for i := 1; i < len(words); i++ {
+		if strings.HasPrefix(strings.Join(words[i:], " "), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTypo reports whether query is s or a prefix of s with a one-letter mistake,
+// allowing two mistakes for longer queries
+func isTypo(s, query string) bool {
+	allowed := 1
+	if len(query) >= 8 {
+		allowed = 2
+	}
+	if len(query) < 4 {
+		return false
+	}
+	if editDistance(s, query) <= allowed {
+		return true
+	}
+	// Typos while typing the start of a name, e.g. "minex" for "minecraft"
+	for n := len(query) - allowed; n <= len(query)+allowed; n++ {
+		if n > 0 && n <= len(s) && editDistance(s[:n], query) <= allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// subsequenceGaps reports whether the letters of query appear in s in order,
+// and how many letters of s lie between the first and last matched ones
+// beyond the query itself
+func subsequenceGaps(s, query string) (int, bool) {
+	q := []rune(strings.ReplaceAll(query, " ", ""))
+	if len(q) == 0 {
+		return 0, false
+	}
+	first, matched := -1, 0
+	for i, r := range []rune(s) {
+		if r != q[matched] {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		matched++
+		if matched == len(q) {
+			return i - first + 1 - len(q), true
+		}
+	}
+	return 0, false
+}
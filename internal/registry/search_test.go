@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+var searchGames = []Game{
+	{Name: "minecraft", DisplayName: "Minecraft", Description: "Blocks and creepers", Tags: []string{"survival", "sandbox"}},
+	{Name: "minecraft-bedrock", DisplayName: "Minecraft Bedrock", Description: "Minecraft for consoles and phones", Tags: []string{"survival"}},
+	{Name: "cs2", DisplayName: "Counter-Strike 2", Description: "Tactical shooter", Tags: []string{"fps", "steam"}},
+	{Name: "valheim", DisplayName: "Valheim", Description: "Viking survival", Tags: []string{"survival", "steam"}},
+	{Name: "terraria", DisplayName: "Terraria", Description: "2D sandbox adventure", Tags: []string{"sandbox"}},
+	{Name: "craftopia", DisplayName: "Craftopia", Description: "Farming and hunting"},
+	{Name: "factorio", DisplayName: "Factorio", Description: "Build factories"},
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		tags  []string
+		want  []string
+	}{
+		{"exact name before prefix", "minecraft", nil, []string{"minecraft", "minecraft-bedrock"}},
+		{"exact display name", "Minecraft Bedrock", nil, []string{"minecraft-bedrock"}},
+		{"separators ignored", "counter strike", nil, []string{"cs2"}},
+		{"word prefix", "strike", nil, []string{"cs2"}},
+		{"prefix before substring", "craft", nil, []string{"craftopia", "minecraft", "minecraft-bedrock"}},
+		{"typo", "valhem", nil, []string{"valheim"}},
+		{"two typos in a long query", "minceraft", nil, []string{"minecraft", "minecraft-bedrock"}},
+		{"typo while typing", "terar", nil, []string{"terraria"}},
+		{"short queries need no typos", "cs3", nil, nil},
+		{"description", "viking", nil, []string{"valheim"}},
+		{"subsequence", "mcft", nil, []string{"minecraft", "minecraft-bedrock"}},
+		{"tight subsequence first", "fti", nil, []string{"craftopia", "factorio"}},
+		{"tag as query", "survival", nil, []string{"minecraft", "minecraft-bedrock", "valheim"}},
+		{"tag before description", "sandbox", nil, []string{"minecraft", "terraria"}},
+		{"tag filter", "", []string{"steam"}, []string{"cs2", "valheim"}},
+		{"every tag required", "", []string{"survival", "steam"}, []string{"valheim"}},
+		{"tags ignore case", "", []string{"FPS"}, []string{"cs2"}},
+		{"query and tag", "mine", []string{"sandbox"}, []string{"minecraft"}},
+		{"no match", "zzzz", nil, nil},
+		{"no match with tag", "mine", []string{"steam"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, g := range Search(searchGames, tt.query, tt.tags) {
+				got = append(got, g.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q, %q) = %q, want %q", tt.query, tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Counter-Strike_2", "counter strike 2"},
+		{"  Minecraft  ", "minecraft"},
+		{"7 Days to Die", "7 days to die"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.in); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"valheim", "valhiem", 2},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSubsequenceGaps(t *testing.T) {
+	tests := []struct {
+		s, query string
+		gaps     int
+		ok       bool
+	}{
+		{"minecraft", "mcft", 5, true},
+		{"minecraft", "mine", 0, true},
+		{"counter strike 2", "cs 2", 13, true},
+		{"minecraft", "tfm", 0, false},
+		{"minecraft", "", 0, false},
+	}
+	for _, tt := range tests {
+		gaps, ok := subsequenceGaps(tt.s, tt.query)
+		if gaps != tt.gaps || ok != tt.ok {
+			t.Errorf("subsequenceGaps(%q, %q) = %d, %v, want %d, %v", tt.s, tt.query, gaps, ok, tt.gaps, tt.ok)
+		}
+	}
+}
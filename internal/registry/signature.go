@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hostathome/cli/internal/minisign"
+)
+
+// signatureSuffix is appended to a registry file's path to find its detached minisign signature
+const signatureSuffix = ".minisig"
+
+// ErrUnsigned is returned for registry files without a signature when signatures are required
+var ErrUnsigned = errors.New("not signed")
+
+// trustedKeys verify remote registry files. Nil disables verification.
+var trustedKeys []minisign.PublicKey
+
+// RequireSignatures makes remote registry files be refused unless they carry
+// a valid minisign signature by one of keys. Local registries are trusted.
+func RequireSignatures(keys []minisign.PublicKey) {
+	trustedKeys = keys
+}
+
+// VerifyingSignatures reports whether remote registry files must be signed
+func VerifyingSignatures() bool {
+	return trustedKeys != nil
+}
+
+// fetchFile returns a registry file, verifying its signature if required
+func (r *Registry) fetchFile(ctx context.Context, file string) ([]byte, error) {
+	data, err := r.fetchCached(ctx, file, false)
+	if err != nil || trustedKeys == nil || r.Source.Local() {
+		return data, err
+	}
+	if err := r.verify(ctx, file, data, false); err == nil {
+		return data, nil
+	}
+
+	// A file and its signature may have been cached at different times
+	data, err = r.fetchCached(ctx, file, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.verify(ctx, file, data, true); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// verify checks data against the detached signature of file
+func (r *Registry) verify(ctx context.Context, file string, data []byte, force bool) error {
+	sigData, err := r.fetchCached(ctx, file+signatureSuffix, force)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%s is %w", file, ErrUnsigned)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature of %s: %w", file, err)
+	}
+
+	sig, err := minisign.ParseSignature(sigData)
+	if err != nil {
+		return fmt.Errorf("%s%s: %w", file, signatureSuffix, err)
+	}
+	if err := minisign.Verify(trustedKeys, data, sig); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned by a Source when the requested file does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrNotModified is returned by a ConditionalSource when the file still matches the cached copy
+	ErrNotModified = errors.New("not modified")
+)
+
+// Source is a location that registry files can be read from
+type Source interface {
+	// Fetch returns the file at path, relative to the registry root (e.g. "games/minecraft.yaml")
+	Fetch(ctx context.Context, path string) ([]byte, error)
+	// Local reports whether the source lives on the local filesystem
+	Local() bool
+	// String returns the registry location as given by the user
+	String() string
+}
+
+// Validators identify a version of a registry file for conditional requests
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ConditionalSource is a Source that can skip downloading unchanged files
+type ConditionalSource interface {
+	Source
+	// FetchIfChanged returns the file at path and its validators, or
+	// ErrNotModified if it still matches known
+	FetchIfChanged(ctx context.Context, path string, known Validators) ([]byte, Validators, error)
+}
+
+// NewSource parses a registry location into a Source.
+// Accepts http(s):// URLs, file:// URLs and plain directory paths.
+func NewSource(location string) (Source, error) {
+	if location == "" {
+		return nil, fmt.Errorf("registry location cannot be empty")
+	}
+
+	if strings.Contains(location, "://") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry URL: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https":
+			return &httpSource{baseURL: strings.TrimSuffix(location, "/")}, nil
+		case "file":
+			return newDirSource(location, u.Path)
+		default:
+			return nil, fmt.Errorf("unsupported registry scheme %q (use https://, file:// or a directory path)", u.Scheme)
+		}
+	}
+
+	return newDirSource(location, location)
+}
+
+// httpSource reads registry files over HTTP(S)
+type httpSource struct {
+	baseURL string
+}
+
+func (s *httpSource) Fetch(ctx context.Context, path string) ([]byte, error) {
+	data, _, err := s.FetchIfChanged(ctx, path, Validators{})
+	return data, err
+}
+
+func (s *httpSource) FetchIfChanged(ctx context.Context, path string, known Validators) ([]byte, Validators, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/"+path, nil)
+	if err != nil {
+		return nil, Validators{}, err
+	}
+	if known.ETag != "" {
+		req.Header.Set("If-None-Match", known.ETag)
+	}
+	if known.LastModified != "" {
+		req.Header.Set("If-Modified-Since", known.LastModified)
+	}
+
+	resp, err := getHTTPClient().Do(req)
+	if err != nil {
+		return nil, Validators{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, known, ErrNotModified
+	case http.StatusNotFound:
+		return nil, Validators{}, ErrNotFound
+	default:
+		return nil, Validators{}, fmt.Errorf("failed to fetch %s: %s", path, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, Validators{}, err
+	}
+	return data, Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}, nil
+}
+
+func (s *httpSource) Local() bool    { return false }
+func (s *httpSource) String() string { return s.baseURL }
+
+// dirSource reads registry files from a local directory
+type dirSource struct {
+	location string
+	root     string
+}
+
+func newDirSource(location, dir string) (*dirSource, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry path: %w", err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("registry directory not accessible: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("registry path %s is not a directory", root)
+	}
+	return &dirSource{location: location, root: root}, nil
+}
+
+func (s *dirSource) Fetch(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.root, filepath.FromSlash(path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *dirSource) Local() bool    { return true }
+func (s *dirSource) String() string { return s.location }
@@ -1,44 +1,311 @@
 package registry
 
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Game represents a game server definition from the registry
 type Game struct {
-	Name          string    `yaml:"name"`
-	DisplayName   string    `yaml:"display_name"`
-	Description   string    `yaml:"description"`
-	Image         string    `yaml:"image"`
-	Ports         Ports     `yaml:"ports"`
-	InternalPorts Ports     `yaml:"internal_ports"`
-	Protocols     Protocols `yaml:"protocols"`
-	Volumes       []string  `yaml:"volumes"`
-}
-
-// Ports defines port mappings
-type Ports struct {
+	Name        string   `json:"name" yaml:"name"`
+	DisplayName string   `json:"display_name" yaml:"display_name"`
+	Description string   `json:"description" yaml:"description"`
+	Image       string   `json:"image" yaml:"image"`
+	Ports       PortList `json:"ports" yaml:"ports"`
+	Volumes     []string `json:"volumes" yaml:"volumes"`
+	// Tags are lowercase categories such as "survival", "fps" or "steam"
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Shutdown is the graceful stop sequence, if the game supports one
+	Shutdown *Shutdown `json:"shutdown,omitempty" yaml:"shutdown,omitempty"`
+	// Query is the status query protocol, if the game supports one
+	Query *Query `json:"query,omitempty" yaml:"query,omitempty"`
+	// Ready describes how to tell that a started server accepts players
+	Ready *Readiness `json:"ready,omitempty" yaml:"ready,omitempty"`
+	// Healthcheck is the Docker healthcheck for the server's container
+	Healthcheck *Healthcheck `json:"healthcheck,omitempty" yaml:"healthcheck,omitempty"`
+	// Console configures the server console that "hostathome console" attaches to
+	Console *Console `json:"console,omitempty" yaml:"console,omitempty"`
+	// Resources are the recommended container limits, overridable per server
+	Resources *Resources `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// Env lists the environment variables the server image understands
+	Env []EnvVar `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// Registry is the name of the registry the definition was loaded from
+	Registry string `json:"-" yaml:"-"`
+	// Shadows lists lower-priority registries that also define this game
+	Shadows []string `json:"-" yaml:"-"`
+}
+
+// Port is a named port the game server listens on
+type Port struct {
+	Name     string `json:"name" yaml:"name"`
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	// Internal is the port inside the container
+	Internal int `json:"internal" yaml:"internal"`
+	// Host is the default port published on the host
+	Host int `json:"host" yaml:"host"`
+	// Range is the number of consecutive ports starting at Internal and Host (default 1)
+	Range int `json:"range,omitempty" yaml:"range,omitempty"`
+}
+
+// Proto returns the port protocol, defaulting to "tcp" if not set
+func (p Port) Proto() string {
+	if p.Protocol == "" {
+		return "tcp"
+	}
+	return p.Protocol
+}
+
+// Count returns the number of ports covered, at least 1
+func (p Port) Count() int {
+	if p.Range < 1 {
+		return 1
+	}
+	return p.Range
+}
+
+// String formats the host side of the port, e.g. "30065/tcp" or "27015-27020/udp"
+func (p Port) String() string {
+	if p.Count() > 1 {
+		return fmt.Sprintf("%d-%d/%s", p.Host, p.Host+p.Count()-1, p.Proto())
+	}
+	return fmt.Sprintf("%d/%s", p.Host, p.Proto())
+}
+
+// Shutdown describes how to stop a game server gracefully over RCON
+type Shutdown struct {
+	// Warning is an RCON command broadcast to players before stopping.
+	// {seconds} is replaced with the time left, e.g. "say Stopping in {seconds}s".
+	Warning string `json:"warning,omitempty" yaml:"warning,omitempty"`
+	// Countdown lists when to send the warning, in seconds before shutdown
+	Countdown []int `json:"countdown,omitempty" yaml:"countdown,omitempty"`
+	// Commands are RCON commands run in order after the countdown, e.g. save-all and stop
+	Commands []string `json:"commands,omitempty" yaml:"commands,omitempty"`
+	// Timeout is how many seconds the server gets to exit before it is killed
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Query declares how to ask a running server for its status
+type Query struct {
+	// Protocol is "minecraft" (Server List Ping) or "a2s" (Valve Source query)
+	Protocol string `json:"protocol" yaml:"protocol"`
+	// Port is the name of the game port that answers queries (default "player")
+	Port string `json:"port,omitempty" yaml:"port,omitempty"`
+}
+
+// PortName returns the name of the game port that answers queries
+func (q *Query) PortName() string {
+	if q.Port == "" {
+		return "player"
+	}
+	return q.Port
+}
+
+// Readiness describes how to tell that a started server accepts players.
+// Without a log pattern, the query protocol is used if the game has one.
+type Readiness struct {
+	// Log is a regular expression matching the line the server logs once ready
+	Log string `json:"log,omitempty" yaml:"log,omitempty"`
+	// Timeout is how many seconds the server may take to become ready
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Healthcheck describes how Docker checks that a running server is healthy.
+// Either Command or Port is set; times are in seconds and default to Docker's.
+type Healthcheck struct {
+	// Command is a shell command run inside the container; exit code 0 means healthy
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Port is the name of a TCP game port that must accept connections
+	Port        string `json:"port,omitempty" yaml:"port,omitempty"`
+	Interval    int    `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout     int    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Retries     int    `json:"retries,omitempty" yaml:"retries,omitempty"`
+	StartPeriod int    `json:"start_period,omitempty" yaml:"start_period,omitempty"`
+}
+
+// Console describes a game server's console
+type Console struct {
+	// TTY gives the server a terminal, for consoles that need one. The
+	// container's logs then merge stdout and stderr and end lines in CRLF.
+	TTY bool `json:"tty,omitempty" yaml:"tty,omitempty"`
+}
+
+// TTY reports whether the game's console needs a terminal
+func (g *Game) TTY() bool {
+	return g.Console != nil && g.Console.TTY
+}
+
+// Resources are container resource limits. Sizes accept units such as "512m"
+// or "4g". A limit of 0 removes the limit set by the game definition; unset
+// fields keep it.
+type Resources struct {
+	// Memory is the memory limit
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+	// Swap is the swap allowed on top of Memory, or "-1" for unlimited swap
+	Swap string `json:"swap,omitempty" yaml:"swap,omitempty"`
+	// CPUs is how many CPUs the server may use, e.g. 1.5
+	CPUs *float64 `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	// CPUShares is the CPU weight relative to other containers (Docker's default is 1024)
+	CPUShares *int64 `json:"cpu_shares,omitempty" yaml:"cpu_shares,omitempty"`
+	// PIDs is the maximum number of processes
+	PIDs *int64 `json:"pids,omitempty" yaml:"pids,omitempty"`
+}
+
+// Merge returns r with the limits set in override replacing its own. Removing
+// the memory limit also drops r's swap, which only applies on top of it.
+func (r Resources) Merge(override *Resources) Resources {
+	if override == nil {
+		return r
+	}
+	if override.Memory != "" {
+		r.Memory = override.Memory
+		if override.Memory == "0" {
+			r.Swap = ""
+		}
+	}
+	if override.Swap != "" {
+		r.Swap = override.Swap
+	}
+	if override.CPUs != nil {
+		r.CPUs = override.CPUs
+	}
+	if override.CPUShares != nil {
+		r.CPUShares = override.CPUShares
+	}
+	if override.PIDs != nil {
+		r.PIDs = override.PIDs
+	}
+	return r
+}
+
+// EnvVar is an environment variable a game server can be configured with
+type EnvVar struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Required variables must be set before the server can be created
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+	// Default is used when no value is set for the server
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+	// Secret values are hidden when listed
+	Secret bool `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// EnvVar returns the environment variable with the given name, or nil if the game doesn't declare it
+func (g *Game) EnvVar(name string) *EnvVar {
+	for i := range g.Env {
+		if g.Env[i].Name == name {
+			return &g.Env[i]
+		}
+	}
+	return nil
+}
+
+// HasTag reports whether the game is tagged with tag, ignoring case
+func (g *Game) HasTag(tag string) bool {
+	for _, t := range g.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Port returns the game port with the given name, or nil if there is none
+func (g *Game) Port(name string) *Port {
+	for i := range g.Ports {
+		if g.Ports[i].Name == name {
+			return &g.Ports[i]
+		}
+	}
+	return nil
+}
+
+// PortList is the list of ports in a game definition
+type PortList []Port
+
+// legacyPorts is the original fixed player/rcon port format
+type legacyPorts struct {
 	Player int `yaml:"player"`
 	RCON   int `yaml:"rcon"`
 }
 
-// Protocols defines protocol types for ports
-type Protocols struct {
+// legacyProtocols is the original fixed player/rcon protocol format
+type legacyProtocols struct {
 	Player string `yaml:"player"`
 	RCON   string `yaml:"rcon"`
 }
 
-// DefaultProtocol returns the protocol for a port name, defaulting to "tcp" if not set
-// Returns "tcp" if port name is invalid
-func (p Protocols) DefaultProtocol(port string) string {
-	switch port {
-	case "player":
-		if p.Player != "" {
-			return p.Player
-		}
-	case "rcon":
-		if p.RCON != "" {
-			return p.RCON
+// UnmarshalYAML accepts a list of ports, or the original map of player and
+// rcon host ports (completed from internal_ports and protocols by Game)
+func (pl *PortList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.SequenceNode:
+		return value.Decode((*[]Port)(pl))
+	case yaml.MappingNode:
+		var host legacyPorts
+		if err := value.Decode(&host); err != nil {
+			return err
 		}
+		*pl = PortList{{Name: "player", Host: host.Player}, {Name: "rcon", Host: host.RCON}}
+		return nil
 	default:
-		// Invalid port name, log but default to tcp
-		return "tcp"
+		return fmt.Errorf("line %d: ports must be a list or a map", value.Line)
+	}
+}
+
+// UnmarshalYAML decodes a game definition, converting the original
+// ports/internal_ports/protocols format into the port list
+func (g *Game) UnmarshalYAML(value *yaml.Node) error {
+	type plain Game
+	if err := value.Decode((*plain)(g)); err != nil {
+		return err
+	}
+
+	if isLegacyPortMap(value) {
+		var legacy struct {
+			InternalPorts legacyPorts     `yaml:"internal_ports"`
+			Protocols     legacyProtocols `yaml:"protocols"`
+		}
+		if err := value.Decode(&legacy); err != nil {
+			return err
+		}
+
+		var ports PortList
+		for _, p := range g.Ports {
+			switch p.Name {
+			case "player":
+				p.Internal, p.Protocol = legacy.InternalPorts.Player, legacy.Protocols.Player
+			case "rcon":
+				p.Internal, p.Protocol = legacy.InternalPorts.RCON, legacy.Protocols.RCON
+			}
+			if p.Host > 0 || p.Internal > 0 {
+				ports = append(ports, p)
+			}
+		}
+		g.Ports = ports
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range g.Ports {
+		if p.Name == "" {
+			return fmt.Errorf("port without a name in game '%s'", g.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate port '%s' in game '%s'", p.Name, g.Name)
+		}
+		seen[p.Name] = true
+	}
+	return nil
+}
+
+// isLegacyPortMap reports whether a game node uses the original ports map
+func isLegacyPortMap(value *yaml.Node) bool {
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "ports" {
+			return value.Content[i+1].Kind == yaml.MappingNode
+		}
 	}
-	return "tcp"
+	return false
 }
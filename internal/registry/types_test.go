@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func float(v float64) *float64 { return &v }
+func int64p(v int64) *int64    { return &v }
+
+func TestResourcesMerge(t *testing.T) {
+	game := Resources{Memory: "4g", Swap: "1g", CPUs: float(2), CPUShares: int64p(512), PIDs: int64p(256)}
+
+	tests := []struct {
+		name     string
+		base     Resources
+		override *Resources
+		want     Resources
+	}{
+		{"no override", game, nil, game},
+		{"empty override", game, &Resources{}, game},
+		{
+			"override replaces",
+			game,
+			&Resources{Memory: "8g", CPUs: float(4), PIDs: int64p(1024)},
+			Resources{Memory: "8g", Swap: "1g", CPUs: float(4), CPUShares: int64p(512), PIDs: int64p(1024)},
+		},
+		{
+			"zero removes",
+			game,
+			&Resources{CPUs: float(0), CPUShares: int64p(0), PIDs: int64p(0)},
+			Resources{Memory: "4g", Swap: "1g", CPUs: float(0), CPUShares: int64p(0), PIDs: int64p(0)},
+		},
+		{"zero memory drops swap", game, &Resources{Memory: "0"}, Resources{Memory: "0", CPUs: float(2), CPUShares: int64p(512), PIDs: int64p(256)}},
+		{
+			"zero memory keeps new swap",
+			game,
+			&Resources{Memory: "0", Swap: "-1"},
+			Resources{Memory: "0", Swap: "-1", CPUs: float(2), CPUShares: int64p(512), PIDs: int64p(256)},
+		},
+		{"swap only", game, &Resources{Swap: "2g"}, Resources{Memory: "4g", Swap: "2g", CPUs: float(2), CPUShares: int64p(512), PIDs: int64p(256)}},
+		{"no game limits", Resources{}, &Resources{Memory: "2g", PIDs: int64p(0)}, Resources{Memory: "2g", PIDs: int64p(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.base.Merge(tt.override); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %s, want %s", formatResources(got), formatResources(tt.want))
+			}
+		})
+	}
+}
+
+// formatResources renders resources with their pointers resolved
+func formatResources(r Resources) string {
+	s := fmt.Sprintf("{memory:%q swap:%q", r.Memory, r.Swap)
+	if r.CPUs != nil {
+		s += fmt.Sprintf(" cpus:%g", *r.CPUs)
+	}
+	if r.CPUShares != nil {
+		s += fmt.Sprintf(" cpu_shares:%d", *r.CPUShares)
+	}
+	if r.PIDs != nil {
+		s += fmt.Sprintf(" pids:%d", *r.PIDs)
+	}
+	return s + "}"
+}
+
+func TestGamePorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    PortList
+		wantErr string
+	}{
+		{
+			"legacy",
+			`ports: {player: 25565, rcon: 25575}
+internal_ports: {player: 25565, rcon: 25575}
+protocols: {player: tcp, rcon: tcp}`,
+			PortList{
+				{Name: "player", Protocol: "tcp", Internal: 25565, Host: 25565},
+				{Name: "rcon", Protocol: "tcp", Internal: 25575, Host: 25575},
+			},
+			"",
+		},
+		{
+			"legacy without rcon",
+			`ports: {player: 2457}
+internal_ports: {player: 2456}
+protocols: {player: udp}`,
+			PortList{{Name: "player", Protocol: "udp", Internal: 2456, Host: 2457}},
+			"",
+		},
+		{
+			"legacy without internal ports",
+			`ports: {player: 25565}`,
+			PortList{{Name: "player", Host: 25565}},
+			"",
+		},
+		{
+			"list",
+			`ports:
+  - {name: game, protocol: udp, internal: 27015, host: 27016, range: 3}
+  - {name: rcon, internal: 27020, host: 27020}`,
+			PortList{
+				{Name: "game", Protocol: "udp", Internal: 27015, Host: 27016, Range: 3},
+				{Name: "rcon", Internal: 27020, Host: 27020},
+			},
+			"",
+		},
+		{
+			"list ignores legacy fields",
+			`ports:
+  - {name: player, internal: 7777, host: 7777}
+internal_ports: {player: 25565, rcon: 25575}
+protocols: {player: udp}`,
+			PortList{{Name: "player", Internal: 7777, Host: 7777}},
+			"",
+		},
+		{"empty list", `ports: []`, nil, ""},
+		{"empty map", `ports: {}`, nil, ""},
+		{"no ports", `image: example`, nil, ""},
+		{"scalar", `ports: 25565`, nil, "must be a list or a map"},
+		{"unnamed port", `ports: [{internal: 25565, host: 25565}]`, nil, "without a name"},
+		{"duplicate", `ports: [{name: game, host: 1}, {name: game, host: 2}]`, nil, "duplicate port 'game'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var game Game
+			err := yaml.Unmarshal([]byte("name: test\n"+tt.yaml), &game)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if len(game.Ports) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(game.Ports, tt.want) {
+				t.Errorf("ports = %+v, want %+v", game.Ports, tt.want)
+			}
+		})
+	}
+}
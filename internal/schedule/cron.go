@@ -0,0 +1,148 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds how far ahead Next looks for a matching time
+const maxSearch = 366 * 24 * time.Hour
+
+// aliases are shorthand schedules accepted in place of a cron expression
+var aliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// Schedule is a parsed five-field cron expression (minute hour day-of-month month day-of-week)
+type Schedule struct {
+	expr                          string
+	minute, hour, dom, month, dow map[int]bool
+	domAny, dowAny                bool
+}
+
+// Parse parses a cron expression such as "0 */6 * * *" or an alias such as "@daily"
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if alias, ok := aliases[spec]; ok {
+		spec = alias
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields (minute hour day month weekday)", expr)
+	}
+
+	s := &Schedule{expr: expr}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid minute in %q: %w", expr, err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid hour in %q: %w", expr, err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid day of month in %q: %w", expr, err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid month in %q: %w", expr, err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid day of week in %q: %w", expr, err)
+	}
+	// Both 0 and 7 mean Sunday
+	if s.dow[7] {
+		s.dow[0] = true
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+
+	return s, nil
+}
+
+// parseField parses a comma-separated list of values, ranges (a-b) and steps (*/n, a-b/n)
+func parseField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid value %q", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("invalid value %q", to)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("%q out of range (%d-%d)", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// matches reports whether t falls on the schedule (to the minute)
+func (s *Schedule) matches(t time.Time) bool {
+	if !s.minute[t.Minute()] || !s.hour[t.Hour()] || !s.month[int(t.Month())] {
+		return false
+	}
+
+	// Like cron, when both day fields are restricted either one may match
+	domMatch := s.dom[t.Day()]
+	dowMatch := s.dow[int(t.Weekday())]
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowMatch
+	case s.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// Next returns the first time strictly after t that falls on the schedule,
+// or the zero time if there is none within a year. The schedule is evaluated
+// in t's location, so pass local times.
+func (s *Schedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	end := t.Add(maxSearch)
+	for ; next.Before(end); next = next.Add(time.Minute) {
+		if s.matches(next) {
+			return next
+		}
+	}
+	return time.Time{}
+}
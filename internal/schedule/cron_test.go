@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@yearly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"*/x * * * *",
+	}
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	for _, expr := range []string{"@daily", "0 */6 * * *"} {
+		s, err := Parse("  " + expr + " ")
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", expr, err)
+		}
+		if s.String() != expr {
+			t.Errorf("String() = %q, want %q", s.String(), expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"step", "*/15 * * * *", at(2025, 1, 1, 10, 7), at(2025, 1, 1, 10, 15)},
+		{"strictly after", "0 3 * * *", at(2025, 1, 1, 3, 0), at(2025, 1, 2, 3, 0)},
+		{"seconds ignored", "0 * * * *", at(2025, 1, 1, 10, 59).Add(30 * time.Second), at(2025, 1, 1, 11, 0)},
+		{"daily alias", "@daily", at(2025, 1, 1, 12, 30), at(2025, 1, 2, 0, 0)},
+		{"hourly alias", "@hourly", at(2025, 1, 1, 10, 0), at(2025, 1, 1, 11, 0)},
+		{"monthly alias", "@monthly", at(2025, 1, 15, 0, 0), at(2025, 2, 1, 0, 0)},
+		{"sunday as 0", "0 0 * * 0", at(2025, 1, 1, 0, 0), at(2025, 1, 5, 0, 0)},
+		{"sunday as 7", "0 0 * * 7", at(2025, 1, 1, 0, 0), at(2025, 1, 5, 0, 0)},
+		{"list", "30 4 1,15 * *", at(2025, 1, 2, 0, 0), at(2025, 1, 15, 4, 30)},
+		{"range step", "0 9-17/4 * * *", at(2025, 1, 1, 10, 0), at(2025, 1, 1, 13, 0)},
+		{"day of month or weekday", "0 0 13 * 5", at(2025, 1, 1, 0, 0), at(2025, 1, 3, 0, 0)},
+		{"weekday in month", "0 12 * 2 1-5", at(2025, 1, 10, 0, 0), at(2025, 2, 3, 12, 0)},
+		{"year rollover", "0 0 1 1 *", at(2025, 6, 1, 0, 0), at(2026, 1, 1, 0, 0)},
+		{"leap day", "0 0 29 2 *", at(2027, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"never", "0 0 31 2 *", at(2025, 1, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextUsesLocation(t *testing.T) {
+	s, err := Parse("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, zone)
+	want := time.Date(2025, 1, 1, 3, 0, 0, 0, zone)
+	if got := s.Next(from); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", from, got, want)
+	}
+	// The same instant in UTC is evaluated against UTC hours
+	if got := s.Next(from.UTC()); !got.Equal(want.Add(2 * time.Hour)) {
+		t.Errorf("Next(%v) = %v, want %v", from.UTC(), got, want.Add(2*time.Hour))
+	}
+}
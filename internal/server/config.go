@@ -0,0 +1,71 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPath returns the path of a server instance's game config
+func ConfigPath(instance string) string {
+	return filepath.Join(Dir(instance), "configs", "config.yaml")
+}
+
+// RCONPassword returns the RCON password from a server's configs/config.yaml.
+// Game images name the setting differently, so rcon_password, rcon-password and
+// rconPassword are accepted at any depth, as is a password key inside an rcon section.
+func RCONPassword(instance string) (string, error) {
+	path := ConfigPath(instance)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("server config %s not found", path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var cfg map[string]any
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	if password, ok := findRCONPassword(cfg, false); ok && password != "" {
+		return password, nil
+	}
+	return "", fmt.Errorf("no RCON password set in %s", path)
+}
+
+// findRCONPassword searches a config section for the RCON password
+func findRCONPassword(section map[string]any, inRCON bool) (string, bool) {
+	for key, value := range section {
+		k := strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(key))
+		if k == "rconpassword" || (inRCON && k == "password") {
+			if s, ok := scalarString(value); ok {
+				return s, true
+			}
+		}
+	}
+	for key, value := range section {
+		if sub, ok := value.(map[string]any); ok {
+			if password, ok := findRCONPassword(sub, strings.EqualFold(key, "rcon")); ok {
+				return password, true
+			}
+		}
+	}
+	return "", false
+}
+
+// scalarString formats a YAML scalar, since numeric passwords decode as numbers
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int, float64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envFile holds a server's environment variables. It is kept apart from the
+// manifest because values can be secrets, and is only readable by the owner.
+const envFile = "env.yaml"
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateEnvName checks that name can be used as an environment variable
+func ValidateEnvName(name string) error {
+	if !envNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid variable name '%s' (letters, digits and underscores, not starting with a digit)", name)
+	}
+	return nil
+}
+
+// LoadEnv reads the environment variables set for a server instance. A missing file yields none.
+func LoadEnv(instance string) (map[string]string, error) {
+	env := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(Dir(instance), envFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return env, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", envFile, err)
+	}
+	if env == nil {
+		env = make(map[string]string)
+	}
+	return env, nil
+}
+
+// SaveEnv writes the environment variables of a server instance, readable only by the owner
+func SaveEnv(instance string, env map[string]string) error {
+	data, err := yaml.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	dir := Dir(instance)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Write to a new file so the permissions apply even if the old one was readable
+	path := filepath.Join(dir, envFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
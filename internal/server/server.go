@@ -0,0 +1,162 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hostathome/cli/internal/registry"
+	"gopkg.in/yaml.v3"
+)
+
+const manifestFile = "server.yaml"
+
+// Manifest holds per-server settings persisted in <instance>-server/server.yaml
+type Manifest struct {
+	// Game is the registry game the instance runs
+	Game string `yaml:"game"`
+	// Ports are the host ports pinned with --port. They override the game's
+	// default ports, and a conflict is an error unless --auto-ports is given.
+	Ports map[string]int `yaml:"ports,omitempty"`
+	// AllocatedPorts are the host ports picked for the other ports when the
+	// container was last created. They are reused while free so the server
+	// keeps its address, but are moved when taken.
+	AllocatedPorts map[string]int `yaml:"allocated_ports,omitempty"`
+	// Backup configures scheduled backups, run by "hostathome agent"
+	Backup *BackupSchedule `yaml:"backup,omitempty"`
+	// Resources override the game's recommended container limits
+	Resources *registry.Resources `yaml:"resources,omitempty"`
+}
+
+// BackupSchedule configures when a server is backed up and which backups are kept
+type BackupSchedule struct {
+	// Cron is a five-field cron expression or an alias such as "@daily"
+	Cron string `yaml:"cron"`
+	// Mode is "pause" or "stop" to quiesce the container while backing up, or empty
+	Mode      string    `yaml:"mode,omitempty"`
+	Retention Retention `yaml:"retention,omitempty"`
+}
+
+// Retention decides which backups survive pruning. A backup is kept if any rule keeps it.
+type Retention struct {
+	// KeepLast keeps the newest N backups
+	KeepLast int `yaml:"keep_last,omitempty"`
+	// KeepDaily keeps the newest backup of each of the last N days
+	KeepDaily int `yaml:"keep_daily,omitempty"`
+	// KeepWeekly keeps the newest backup of each of the last N weeks
+	KeepWeekly int `yaml:"keep_weekly,omitempty"`
+}
+
+// IsZero reports whether no retention rule is set, in which case nothing is pruned
+func (r Retention) IsZero() bool {
+	return r.KeepLast == 0 && r.KeepDaily == 0 && r.KeepWeekly == 0
+}
+
+// SetPort overrides the host port for a named game port
+func (m *Manifest) SetPort(name string, port int) {
+	if m.Ports == nil {
+		m.Ports = make(map[string]int)
+	}
+	m.Ports[name] = port
+}
+
+// Dir returns the data directory for a server instance
+func Dir(instance string) string {
+	return fmt.Sprintf("./%s-server", instance)
+}
+
+// List returns the instances with a server directory and manifest in the current directory
+func List() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join("*-server", manifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []string
+	for _, m := range matches {
+		instances = append(instances, strings.TrimSuffix(filepath.Dir(m), "-server"))
+	}
+	return instances, nil
+}
+
+// Installed returns the game of every server directory in the current directory,
+// keyed by instance. Directories without a readable manifest (servers that have
+// not been run yet) are assumed to hold the game they are named after.
+func Installed() (map[string]string, error) {
+	matches, err := filepath.Glob("*-server")
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string]string)
+	for _, dir := range matches {
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		instance := strings.TrimSuffix(dir, "-server")
+		installed[instance] = instance
+		if manifest, err := LoadManifest(instance); err == nil && manifest.Game != "" {
+			installed[instance] = manifest.Game
+		}
+	}
+	return installed, nil
+}
+
+// LoadManifest reads the manifest of a server instance. A missing manifest yields an empty one.
+func LoadManifest(instance string) (*Manifest, error) {
+	m := &Manifest{}
+	data, err := os.ReadFile(filepath.Join(Dir(instance), manifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", manifestFile, err)
+	}
+	return m, nil
+}
+
+// SaveManifest writes the manifest of a server instance
+func SaveManifest(instance string, m *Manifest) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	dir := Dir(instance)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, manifestFile), data, 0644)
+}
+
+// DiskUsage returns the total size of the files in a server instance's directory
+func DiskUsage(instance string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(Dir(instance), func(path string, d os.DirEntry, err error) error {
+		// The server may delete files while we walk
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/moby/term"
+)
+
+// LineReader reads lines from stdin. On a terminal it supports basic line
+// editing and recalling earlier lines with the arrow keys.
+type LineReader struct {
+	prompt  string
+	history []string
+	in      *bufio.Reader
+}
+
+// NewLineReader creates a LineReader that shows prompt and starts with history (oldest first)
+func NewLineReader(prompt string, history []string) *LineReader {
+	return &LineReader{
+		prompt:  prompt,
+		history: history,
+		in:      bufio.NewReader(os.Stdin),
+	}
+}
+
+// History returns the lines entered so far, oldest first, including the initial history
+func (r *LineReader) History() []string {
+	return r.history
+}
+
+// ReadLine reads one line. It returns io.EOF at end of input or when Ctrl+D is
+// pressed on an empty line; Ctrl+C discards the current line.
+func (r *LineReader) ReadLine() (string, error) {
+	fd, isTerm := term.GetFdInfo(os.Stdin)
+	if !isTerm {
+		fmt.Fprint(out, r.prompt)
+		line, err := r.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		return r.remember(strings.TrimRight(line, "\r\n")), nil
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.RestoreTerminal(fd, state)
+
+	var buf []rune
+	pos := 0
+	hist := len(r.history)
+	redraw := func() {
+		// Raw mode disables output processing, so lines end with \r\n
+		fmt.Fprintf(out, "\r%s%s\x1b[K", r.prompt, string(buf))
+		if back := len(buf) - pos; back > 0 {
+			fmt.Fprintf(out, "\x1b[%dD", back)
+		}
+	}
+	redraw()
+
+	for {
+		c, _, err := r.in.ReadRune()
+		if err != nil {
+			fmt.Fprint(out, "\r\n")
+			return "", err
+		}
+
+		switch c {
+		case '\r', '\n':
+			fmt.Fprint(out, "\r\n")
+			return r.remember(string(buf)), nil
+		case 3: // Ctrl+C
+			fmt.Fprint(out, "^C\r\n")
+			buf, pos = nil, 0
+			hist = len(r.history)
+		case 4: // Ctrl+D
+			if len(buf) == 0 {
+				fmt.Fprint(out, "\r\n")
+				return "", io.EOF
+			}
+		case 127, 8: // Backspace
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case 1: // Ctrl+A
+			pos = 0
+		case 5: // Ctrl+E
+			pos = len(buf)
+		case 21: // Ctrl+U
+			buf, pos = buf[pos:], 0
+		case 27: // Escape sequence
+			seq := r.readEscape()
+			switch seq {
+			case "[A": // Up
+				if hist > 0 {
+					hist--
+					buf = []rune(r.history[hist])
+					pos = len(buf)
+				}
+			case "[B": // Down
+				if hist < len(r.history) {
+					hist++
+					buf = nil
+					if hist < len(r.history) {
+						buf = []rune(r.history[hist])
+					}
+					pos = len(buf)
+				}
+			case "[C": // Right
+				if pos < len(buf) {
+					pos++
+				}
+			case "[D": // Left
+				if pos > 0 {
+					pos--
+				}
+			case "[H", "OH":
+				pos = 0
+			case "[F", "OF":
+				pos = len(buf)
+			}
+		default:
+			if c >= ' ' {
+				buf = append(buf[:pos], append([]rune{c}, buf[pos:]...)...)
+				pos++
+			}
+		}
+		redraw()
+	}
+}
+
+// readEscape reads the rest of a CSI or SS3 escape sequence, e.g. "[A" for the up arrow
+func (r *LineReader) readEscape() string {
+	var seq strings.Builder
+	for {
+		c, _, err := r.in.ReadRune()
+		if err != nil {
+			return seq.String()
+		}
+		seq.WriteRune(c)
+		// The introducer is followed by parameters and ends with a letter or ~
+		if seq.Len() > 1 && (c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '~') {
+			return seq.String()
+		}
+		if seq.Len() == 1 && c != '[' && c != 'O' {
+			return seq.String()
+		}
+	}
+}
+
+// remember adds a non-empty line to the history unless it repeats the previous line
+func (r *LineReader) remember(line string) string {
+	if strings.TrimSpace(line) != "" && (len(r.history) == 0 || r.history[len(r.history)-1] != line) {
+		r.history = append(r.history, line)
+	}
+	return line
+}
+
+// ReadSecret prompts for a value without echoing it when stdin is a terminal
+func ReadSecret(prompt string) (string, error) {
+	fmt.Fprint(out, prompt)
+	fd, isTerm := term.GetFdInfo(os.Stdin)
+	if isTerm {
+		state, err := term.SaveState(fd)
+		if err != nil {
+			return "", err
+		}
+		if err := term.DisableEcho(fd, state); err != nil {
+			return "", err
+		}
+		defer func() {
+			term.RestoreTerminal(fd, state)
+			fmt.Fprintln(out)
+		}()
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
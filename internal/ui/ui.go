@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sync"
 	"time"
@@ -33,9 +34,50 @@ const (
 	SymbolDocker  = "🐳"
 )
 
-// isTerminal checks if stdout is a terminal
+// out receives messages, tables and spinners
+var out io.Writer = os.Stdout
+
+var (
+	// outMu keeps messages and spinner frames from interleaving
+	outMu sync.Mutex
+	// spinning is the number of spinners drawing on a terminal
+	spinning int
+)
+
+// SetOutput sends messages, tables and spinners to w instead of stdout, e.g.
+// to stderr while stdout carries data for scripts
+func SetOutput(w io.Writer) {
+	out = w
+}
+
+// Printf writes formatted text to the message output
+func Printf(format string, args ...any) {
+	write(fmt.Sprintf(format, args...))
+}
+
+// Println writes a line to the message output
+func Println(args ...any) {
+	write(fmt.Sprintln(args...))
+}
+
+// write writes text to the message output. A spinner line being drawn is
+// cleared first; the spinner redraws itself below the text.
+func write(text string) {
+	outMu.Lock()
+	defer outMu.Unlock()
+	if spinning > 0 {
+		fmt.Fprint(out, "\r\033[K")
+	}
+	fmt.Fprint(out, text)
+}
+
+// isTerminal checks if the message output is a terminal
 func isTerminal() bool {
-	fi, err := os.Stdout.Stat()
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
 	if err != nil {
 		return false
 	}
@@ -53,46 +95,60 @@ func color(c, text string) string {
 // Success prints a success message
 func Success(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s %s\n", color(Green, SymbolCheck), msg)
+	write(fmt.Sprintf("%s %s\n", color(Green, SymbolCheck), msg))
 }
 
 // Error prints an error message
 func Error(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s %s\n", color(Red, SymbolCross), msg)
+	write(fmt.Sprintf("%s %s\n", color(Red, SymbolCross), msg))
 }
 
 // Warning prints a warning message
 func Warning(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s %s\n", color(Yellow, SymbolWarning), msg)
+	write(fmt.Sprintf("%s %s\n", color(Yellow, SymbolWarning), msg))
 }
 
 // Info prints an info message
 func Info(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s %s\n", color(Blue, SymbolInfo), msg)
+	write(fmt.Sprintf("%s %s\n", color(Blue, SymbolInfo), msg))
 }
 
 // Step prints a step being performed
 func Step(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s %s\n", color(Cyan, SymbolArrow), msg)
+	write(fmt.Sprintf("%s %s\n", color(Cyan, SymbolArrow), msg))
 }
 
 // Title prints a bold title
 func Title(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
 	if isTerminal() {
-		fmt.Printf("%s%s%s\n", Bold, msg, Reset)
+		write(fmt.Sprintf("%s%s%s\n", Bold, msg, Reset))
 	} else {
-		fmt.Println(msg)
+		write(msg + "\n")
 	}
 }
 
 // Detail prints an indented detail line
 func Detail(label, value string) {
-	fmt.Printf("   %s %s\n", color(Gray, label+":"), value)
+	write(fmt.Sprintf("   %s %s\n", color(Gray, label+":"), value))
+}
+
+// FormatBytes formats a byte count in human-readable units (e.g. "1.5 MB")
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 // Spinner represents a loading spinner
@@ -118,7 +174,7 @@ func NewSpinner(message string) *Spinner {
 // Start begins the spinner animation
 func (s *Spinner) Start() {
 	if !isTerminal() {
-		fmt.Printf("%s %s...\n", SymbolArrow, s.message)
+		fmt.Fprintf(out, "%s %s...\n", SymbolArrow, s.message)
 		return
 	}
 	s.mu.Lock()
@@ -128,6 +184,9 @@ func (s *Spinner) Start() {
 	}
 	s.running = true
 	s.mu.Unlock()
+	outMu.Lock()
+	spinning++
+	outMu.Unlock()
 
 	go func() {
 		i := 0
@@ -136,7 +195,16 @@ func (s *Spinner) Start() {
 			case <-s.done:
 				return
 			default:
-				fmt.Printf("\r%s %s %s", color(Cyan, s.frames[i]), s.message, color(Gray, "..."))
+				// Never draw after stop, which would leave a frame on the line
+				s.mu.Lock()
+				if !s.running {
+					s.mu.Unlock()
+					return
+				}
+				outMu.Lock()
+				fmt.Fprintf(out, "\r%s %s %s", color(Cyan, s.frames[i]), s.message, color(Gray, "..."))
+				outMu.Unlock()
+				s.mu.Unlock()
 				i = (i + 1) % len(s.frames)
 				time.Sleep(s.interval)
 			}
@@ -150,6 +218,9 @@ func (s *Spinner) stop() {
 	defer s.mu.Unlock()
 	if s.running {
 		s.running = false
+		outMu.Lock()
+		spinning--
+		outMu.Unlock()
 		// Non-blocking send to buffered channel
 		select {
 		case s.done <- struct{}{}:
@@ -162,7 +233,7 @@ func (s *Spinner) stop() {
 func (s *Spinner) Stop(success bool) {
 	s.stop()
 	if isTerminal() {
-		fmt.Print("\r\033[K") // Clear line
+		fmt.Fprint(out, "\r\033[K") // Clear line
 	}
 	if success {
 		Success("%s", s.message)
@@ -175,7 +246,7 @@ func (s *Spinner) Stop(success bool) {
 func (s *Spinner) StopWithMessage(success bool, message string) {
 	s.stop()
 	if isTerminal() {
-		fmt.Print("\r\033[K") // Clear line
+		fmt.Fprint(out, "\r\033[K") // Clear line
 	}
 	if success {
 		Success("%s", message)
@@ -184,6 +255,13 @@ func (s *Spinner) StopWithMessage(success bool, message string) {
 	}
 }
 
+// ClearScreen clears the terminal so output can be redrawn in place
+func ClearScreen() {
+	if isTerminal() {
+		fmt.Fprint(out, "\033[H\033[2J")
+	}
+}
+
 // Table prints a formatted table
 func Table(headers []string, rows [][]string) {
 	if len(headers) == 0 {
@@ -207,18 +285,18 @@ func Table(headers []string, rows [][]string) {
 
 	// Print headers
 	for i, h := range headers {
-		fmt.Printf("%-*s  ", widths[i], color(Bold, h))
+		fmt.Fprintf(out, "%-*s  ", widths[i], color(Bold, h))
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 
 	// Print separator
 	for i := range headers {
 		for j := 0; j < widths[i]; j++ {
-			fmt.Print("─")
+			fmt.Fprint(out, "─")
 		}
-		fmt.Print("  ")
+		fmt.Fprint(out, "  ")
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 
 	// Print rows (only print columns that match headers)
 	for _, row := range rows {
@@ -227,9 +305,9 @@ func Table(headers []string, rows [][]string) {
 			if i < len(row) {
 				cell = row[i]
 			}
-			fmt.Printf("%-*s  ", widths[i], cell)
+			fmt.Fprintf(out, "%-*s  ", widths[i], cell)
 		}
-		fmt.Println()
+		fmt.Fprintln(out)
 	}
 }
 
@@ -241,32 +319,32 @@ func Box(title, content string) {
 	}
 
 	// Top border
-	fmt.Print("╭")
+	fmt.Fprint(out, "╭")
 	for i := 0; i < width; i++ {
-		fmt.Print("─")
+		fmt.Fprint(out, "─")
 	}
-	fmt.Println("╮")
+	fmt.Fprintln(out, "╮")
 
 	// Title
-	fmt.Printf("│ %s%s%s", Bold, title, Reset)
+	fmt.Fprintf(out, "│ %s%s%s", Bold, title, Reset)
 	for i := 0; i < width-len(title)-1; i++ {
-		fmt.Print(" ")
+		fmt.Fprint(out, " ")
 	}
-	fmt.Println("│")
+	fmt.Fprintln(out, "│")
 
 	// Content
 	if content != "" {
-		fmt.Printf("│ %s", content)
+		fmt.Fprintf(out, "│ %s", content)
 		for i := 0; i < width-len(content)-1; i++ {
-			fmt.Print(" ")
+			fmt.Fprint(out, " ")
 		}
-		fmt.Println("│")
+		fmt.Fprintln(out, "│")
 	}
 
 	// Bottom border
-	fmt.Print("╰")
+	fmt.Fprint(out, "╰")
 	for i := 0; i < width; i++ {
-		fmt.Print("─")
+		fmt.Fprint(out, "─")
 	}
-	fmt.Println("╯")
+	fmt.Fprintln(out, "╯")
 }